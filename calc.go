@@ -0,0 +1,720 @@
+// Copyright 2021 Airbus Defence and Space
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package godal
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Expression is a compiled band math expression, as used by Calc.
+//
+// Expressions follow a gdal_calc like syntax, where variables are the names
+// given to the input bands, e.g. "(B4-B3)/(B4+B3)". Supported constructs are:
+//   - numeric literals, e.g. 1, 2.5, 1e-3
+//   - arithmetic operators +, -, *, /, % and ** (or ^) for exponentiation
+//   - comparison operators <, <=, >, >=, ==, != which evaluate to 1 or 0
+//   - logical operators &&, || and ! where any non-zero value is true
+//   - the ternary conditional "cond ? a : b" and its function form where(cond,a,b)
+//   - the functions abs, sqrt, exp, log, log10, sin, cos, tan, asin, acos, atan,
+//     atan2, floor, ceil, round, pow, min, max and isnan
+type Expression struct {
+	src  string
+	root calcNode
+	vars []string
+}
+
+// ParseExpression compiles the given band math expression
+func ParseExpression(expr string) (*Expression, error) {
+	p := &calcParser{src: expr, varIdx: map[string]int{}}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+	root, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != calcTokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d in expression", tok.text, tok.pos)
+	}
+	return &Expression{src: expr, root: root, vars: p.vars}, nil
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.src
+}
+
+// Variables returns the names of the variables referenced by the expression, in
+// order of first appearance
+func (e *Expression) Variables() []string {
+	ret := make([]string, len(e.vars))
+	copy(ret, e.vars)
+	return ret
+}
+
+// Eval evaluates the expression for a single set of variable values. It returns an
+// error if a variable referenced by the expression is missing from values.
+func (e *Expression) Eval(values map[string]float64) (float64, error) {
+	vals := make([]float64, len(e.vars))
+	for i, v := range e.vars {
+		val, ok := values[v]
+		if !ok {
+			return 0, fmt.Errorf("missing value for variable %s", v)
+		}
+		vals[i] = val
+	}
+	return e.root.eval(vals), nil
+}
+
+// evalBlock evaluates the expression over npix pixels. srcs contains one buffer
+// per variable (in the order of e.vars). Pixels for which any of the referenced
+// sources is equal to its nodata value (if not nil) are set to dstNoData.
+func (e *Expression) evalBlock(srcs [][]float64, srcNoData []*float64, dstNoData float64, out []float64) {
+	vals := make([]float64, len(e.vars))
+	for p := range out {
+		nodata := false
+		for v := range srcs {
+			vals[v] = srcs[v][p]
+			if nd := srcNoData[v]; nd != nil &&
+				(vals[v] == *nd || (math.IsNaN(*nd) && math.IsNaN(vals[v]))) {
+				nodata = true
+				break
+			}
+		}
+		if nodata {
+			out[p] = dstNoData
+			continue
+		}
+		out[p] = e.root.eval(vals)
+	}
+}
+
+// calcPixelFunc is the state needed by the godal_calc VRT pixel function
+type calcPixelFunc struct {
+	expr      *Expression
+	sources   []int //index of the vrt source for each expression variable
+	srcNoData []*float64
+	dstNoData float64
+}
+
+var calcPixelFuncs sync.Map
+
+// getCalcPixelFunc returns the (cached) pixel function for the arguments found in
+// a VRT derived band
+func getCalcPixelFunc(expression, variables, srcNoData, dstNoData string) (*calcPixelFunc, error) {
+	key := strings.Join([]string{expression, variables, srcNoData, dstNoData}, "\x00")
+	if pf, ok := calcPixelFuncs.Load(key); ok {
+		return pf.(*calcPixelFunc), nil
+	}
+	expr, err := ParseExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	vars := strings.Split(variables, ",")
+	nds := strings.Split(srcNoData, ",")
+	if len(nds) != len(vars) {
+		return nil, fmt.Errorf("mismatched variables and srcnodata arguments")
+	}
+	pf := &calcPixelFunc{expr: expr}
+	for _, v := range expr.vars {
+		idx := -1
+		for i := range vars {
+			if vars[i] == v {
+				idx = i
+			}
+		}
+		if idx == -1 {
+			return nil, fmt.Errorf("no source for variable %s", v)
+		}
+		pf.sources = append(pf.sources, idx)
+		var nd *float64
+		if nds[idx] != "" {
+			f, err := strconv.ParseFloat(nds[idx], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid srcnodata value %q", nds[idx])
+			}
+			nd = &f
+		}
+		pf.srcNoData = append(pf.srcNoData, nd)
+	}
+	if dstNoData != "" {
+		if pf.dstNoData, err = strconv.ParseFloat(dstNoData, 64); err != nil {
+			return nil, fmt.Errorf("invalid nodata value %q", dstNoData)
+		}
+	}
+	calcPixelFuncs.Store(key, pf)
+	return pf, nil
+}
+
+func (pf *calcPixelFunc) eval(srcs [][]float64, out []float64) error {
+	vsrcs := make([][]float64, len(pf.sources))
+	for i, s := range pf.sources {
+		if s >= len(srcs) {
+			return fmt.Errorf("missing source %d", s+1)
+		}
+		vsrcs[i] = srcs[s]
+	}
+	pf.expr.evalBlock(vsrcs, pf.srcNoData, pf.dstNoData, out)
+	return nil
+}
+
+func calcFormatFloat(f float64) string {
+	if math.IsNaN(f) {
+		return "nan"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+type calcVRTSourceFilename struct {
+	RelativeToVRT int    `xml:"relativeToVRT,attr"`
+	Name          string `xml:",chardata"`
+}
+
+type calcVRTSource struct {
+	Filename calcVRTSourceFilename `xml:"SourceFilename"`
+	Band     int                   `xml:"SourceBand"`
+}
+
+type calcVRTArguments struct {
+	Expression string `xml:"expression,attr"`
+	Variables  string `xml:"variables,attr"`
+	SrcNoData  string `xml:"srcnodata,attr"`
+	NoData     string `xml:"nodata,attr,omitempty"`
+}
+
+type calcVRTBand struct {
+	DataType               string           `xml:"dataType,attr"`
+	Band                   int              `xml:"band,attr"`
+	SubClass               string           `xml:"subClass,attr"`
+	NoDataValue            string           `xml:",omitempty"`
+	PixelFunctionType      string           `xml:"PixelFunctionType"`
+	PixelFunctionArguments calcVRTArguments `xml:"PixelFunctionArguments"`
+	SourceTransferType     string           `xml:"SourceTransferType"`
+	Sources                []calcVRTSource  `xml:"SimpleSource"`
+}
+
+type calcVRTDataset struct {
+	XMLName      xml.Name    `xml:"VRTDataset"`
+	XSize        int         `xml:"rasterXSize,attr"`
+	YSize        int         `xml:"rasterYSize,attr"`
+	SRS          string      `xml:",omitempty"`
+	GeoTransform string      `xml:",omitempty"`
+	Band         calcVRTBand `xml:"VRTRasterBand"`
+}
+
+type calcOpts struct {
+	driver                 DriverName
+	dtype                  DataType
+	nodata                 float64
+	hasNoData              bool
+	blockSizeX, blockSizeY int
+	creation               []string
+	config                 []string
+	errorHandler           ErrorHandler
+}
+
+// CalcOption is an option that can be passed to Calc()
+//
+// Available CalcOptions are:
+//   - a DriverName for the output dataset (defaults to GTiff). VRT creates a derived band
+//     instead of computing the output pixels
+//   - OutputType to set the output datatype (defaults to Float32)
+//   - OutputNoData to set the output nodata value
+//   - BlockSize to set the size of the windows the expression is evaluated on
+//   - CreationOption
+//   - ConfigOption
+//   - ErrLogger
+type CalcOption interface {
+	setCalcOpt(co *calcOpts)
+}
+
+type outputTypeOpt struct {
+	dtype DataType
+}
+
+func (ot outputTypeOpt) setCalcOpt(co *calcOpts) {
+	co.dtype = ot.dtype
+}
+func (ot outputTypeOpt) setPrefetchOpt(po *prefetchOpts) {
+	po.dtype = ot.dtype
+}
+
+// OutputType sets the datatype of the created output, or of the buffers returned
+// by Dataset.Prefetch
+func OutputType(dtype DataType) interface {
+	CalcOption
+	PrefetchOption
+} {
+	return outputTypeOpt{dtype}
+}
+
+type outputNoDataOpt struct {
+	nodata float64
+}
+
+func (ond outputNoDataOpt) setCalcOpt(co *calcOpts) {
+	co.nodata = ond.nodata
+	co.hasNoData = true
+}
+
+// OutputNoData sets the nodata value of the created output. Output pixels for which
+// any of the inputs is nodata are set to this value. If not set, the nodata value
+// of the first input band that has one is used.
+func OutputNoData(nd float64) interface {
+	CalcOption
+} {
+	return outputNoDataOpt{nd}
+}
+
+type blockSizeOpt struct {
+	sx, sy int
+}
+
+func (bso blockSizeOpt) setCalcOpt(co *calcOpts) {
+	co.blockSizeX = bso.sx
+	co.blockSizeY = bso.sy
+}
+
+// BlockSize sets the size of the windows that are processed at once. It defaults
+// to the block size of the output dataset.
+func BlockSize(sx, sy int) interface {
+	CalcOption
+} {
+	return blockSizeOpt{sx, sy}
+}
+
+type calcNode interface {
+	eval(vals []float64) float64
+}
+
+type calcConst float64
+
+func (c calcConst) eval(vals []float64) float64 {
+	return float64(c)
+}
+
+type calcVar int
+
+func (v calcVar) eval(vals []float64) float64 {
+	return vals[v]
+}
+
+type calcUnary struct {
+	op string
+	x  calcNode
+}
+
+func (u calcUnary) eval(vals []float64) float64 {
+	x := u.x.eval(vals)
+	switch u.op {
+	case "-":
+		return -x
+	case "!":
+		return calcBool(x == 0)
+	}
+	return x
+}
+
+type calcBinary struct {
+	op   string
+	l, r calcNode
+}
+
+func (b calcBinary) eval(vals []float64) float64 {
+	l := b.l.eval(vals)
+	switch b.op {
+	case "&&":
+		if l == 0 {
+			return 0
+		}
+		return calcBool(b.r.eval(vals) != 0)
+	case "||":
+		if l != 0 {
+			return 1
+		}
+		return calcBool(b.r.eval(vals) != 0)
+	}
+	r := b.r.eval(vals)
+	switch b.op {
+	case "+":
+		return l + r
+	case "-":
+		return l - r
+	case "*":
+		return l * r
+	case "/":
+		return l / r
+	case "%":
+		return math.Mod(l, r)
+	case "**":
+		return math.Pow(l, r)
+	case "<":
+		return calcBool(l < r)
+	case "<=":
+		return calcBool(l <= r)
+	case ">":
+		return calcBool(l > r)
+	case ">=":
+		return calcBool(l >= r)
+	case "==":
+		return calcBool(l == r)
+	case "!=":
+		return calcBool(l != r)
+	}
+	panic("unknown operator " + b.op)
+}
+
+type calcTernary struct {
+	cond, a, b calcNode
+}
+
+func (t calcTernary) eval(vals []float64) float64 {
+	if t.cond.eval(vals) != 0 {
+		return t.a.eval(vals)
+	}
+	return t.b.eval(vals)
+}
+
+type calcCall struct {
+	fn   func(args []float64) float64
+	args []calcNode
+}
+
+func (c calcCall) eval(vals []float64) float64 {
+	args := make([]float64, len(c.args))
+	for i := range c.args {
+		args[i] = c.args[i].eval(vals)
+	}
+	return c.fn(args)
+}
+
+func calcBool(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+type calcFunc struct {
+	minArgs, maxArgs int //maxArgs<0 means variadic
+	fn               func(args []float64) float64
+}
+
+func calcMath1(fn func(float64) float64) calcFunc {
+	return calcFunc{1, 1, func(args []float64) float64 { return fn(args[0]) }}
+}
+
+var calcFuncs = map[string]calcFunc{
+	"abs":   calcMath1(math.Abs),
+	"sqrt":  calcMath1(math.Sqrt),
+	"exp":   calcMath1(math.Exp),
+	"log":   calcMath1(math.Log),
+	"log10": calcMath1(math.Log10),
+	"sin":   calcMath1(math.Sin),
+	"cos":   calcMath1(math.Cos),
+	"tan":   calcMath1(math.Tan),
+	"asin":  calcMath1(math.Asin),
+	"acos":  calcMath1(math.Acos),
+	"atan":  calcMath1(math.Atan),
+	"floor": calcMath1(math.Floor),
+	"ceil":  calcMath1(math.Ceil),
+	"round": calcMath1(math.Round),
+	"isnan": calcMath1(func(x float64) float64 { return calcBool(math.IsNaN(x)) }),
+	"atan2": {2, 2, func(args []float64) float64 { return math.Atan2(args[0], args[1]) }},
+	"pow":   {2, 2, func(args []float64) float64 { return math.Pow(args[0], args[1]) }},
+	"min": {1, -1, func(args []float64) float64 {
+		ret := args[0]
+		for _, a := range args[1:] {
+			ret = math.Min(ret, a)
+		}
+		return ret
+	}},
+	"max": {1, -1, func(args []float64) float64 {
+		ret := args[0]
+		for _, a := range args[1:] {
+			ret = math.Max(ret, a)
+		}
+		return ret
+	}},
+}
+
+type calcTokKind int
+
+const (
+	calcTokEOF calcTokKind = iota
+	calcTokNum
+	calcTokIdent
+	calcTokOp
+)
+
+type calcToken struct {
+	kind calcTokKind
+	text string
+	num  float64
+	pos  int
+}
+
+type calcParser struct {
+	src    string
+	toks   []calcToken
+	cur    int
+	vars   []string
+	varIdx map[string]int
+}
+
+var calcOperators = []string{"**", "<=", ">=", "==", "!=", "&&", "||",
+	"+", "-", "*", "/", "%", "^", "<", ">", "!", "(", ")", ",", "?", ":"}
+
+func (p *calcParser) tokenize() error {
+	s := p.src
+	i := 0
+	for i < len(s) {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(s) && unicode.IsDigit(rune(s[i+1]))):
+			j := i
+			for j < len(s) && (unicode.IsDigit(rune(s[j])) || s[j] == '.') {
+				j++
+			}
+			if j < len(s) && (s[j] == 'e' || s[j] == 'E') {
+				k := j + 1
+				if k < len(s) && (s[k] == '+' || s[k] == '-') {
+					k++
+				}
+				if k < len(s) && unicode.IsDigit(rune(s[k])) {
+					j = k
+					for j < len(s) && unicode.IsDigit(rune(s[j])) {
+						j++
+					}
+				}
+			}
+			num, err := strconv.ParseFloat(s[i:j], 64)
+			if err != nil {
+				return fmt.Errorf("invalid number %q at position %d in expression", s[i:j], i)
+			}
+			p.toks = append(p.toks, calcToken{kind: calcTokNum, text: s[i:j], num: num, pos: i})
+			i = j
+		case c == '_' || unicode.IsLetter(c):
+			j := i
+			for j < len(s) && (s[j] == '_' || unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j]))) {
+				j++
+			}
+			p.toks = append(p.toks, calcToken{kind: calcTokIdent, text: s[i:j], pos: i})
+			i = j
+		default:
+			found := false
+			for _, op := range calcOperators {
+				if strings.HasPrefix(s[i:], op) {
+					p.toks = append(p.toks, calcToken{kind: calcTokOp, text: op, pos: i})
+					i += len(op)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("unexpected character %q at position %d in expression", c, i)
+			}
+		}
+	}
+	p.toks = append(p.toks, calcToken{kind: calcTokEOF, text: "end of expression", pos: len(s)})
+	return nil
+}
+
+func (p *calcParser) peek() calcToken {
+	return p.toks[p.cur]
+}
+
+func (p *calcParser) next() calcToken {
+	tok := p.toks[p.cur]
+	if tok.kind != calcTokEOF {
+		p.cur++
+	}
+	return tok
+}
+
+func (p *calcParser) accept(ops ...string) (string, bool) {
+	tok := p.peek()
+	if tok.kind != calcTokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			p.cur++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *calcParser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		tok := p.peek()
+		return fmt.Errorf("expected %q, got %q at position %d in expression", op, tok.text, tok.pos)
+	}
+	return nil
+}
+
+func (p *calcParser) parseTernary() (calcNode, error) {
+	cond, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept("?"); !ok {
+		return cond, nil
+	}
+	a, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+	if err = p.expect(":"); err != nil {
+		return nil, err
+	}
+	b, err := p.parseTernary()
+	if err != nil {
+		return nil, err
+	}
+	return calcTernary{cond, a, b}, nil
+}
+
+// binary operators by increasing precedence
+var calcPrecedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *calcParser) parseBinary(level int) (calcNode, error) {
+	if level == len(calcPrecedence) {
+		return p.parseUnary()
+	}
+	l, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(calcPrecedence[level]...)
+		if !ok {
+			return l, nil
+		}
+		r, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		l = calcBinary{op, l, r}
+	}
+}
+
+func (p *calcParser) parseUnary() (calcNode, error) {
+	if op, ok := p.accept("-", "+", "!"); ok {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return calcUnary{op, x}, nil
+	}
+	return p.parsePower()
+}
+
+func (p *calcParser) parsePower() (calcNode, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.accept("**", "^"); ok {
+		//right associative, and binds tighter than a unary minus on its left
+		exp, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return calcBinary{"**", base, exp}, nil
+	}
+	return base, nil
+}
+
+func (p *calcParser) parsePrimary() (calcNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case calcTokNum:
+		return calcConst(tok.num), nil
+	case calcTokIdent:
+		if _, ok := p.accept("("); ok {
+			return p.parseCall(tok)
+		}
+		idx, ok := p.varIdx[tok.text]
+		if !ok {
+			idx = len(p.vars)
+			p.vars = append(p.vars, tok.text)
+			p.varIdx[tok.text] = idx
+		}
+		return calcVar(idx), nil
+	case calcTokOp:
+		if tok.text == "(" {
+			x, err := p.parseTernary()
+			if err != nil {
+				return nil, err
+			}
+			if err = p.expect(")"); err != nil {
+				return nil, err
+			}
+			return x, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d in expression", tok.text, tok.pos)
+}
+
+func (p *calcParser) parseCall(name calcToken) (calcNode, error) {
+	var args []calcNode
+	if _, ok := p.accept(")"); !ok {
+		for {
+			arg, err := p.parseTernary()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if _, ok := p.accept(","); !ok {
+				break
+			}
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+	fname := strings.ToLower(name.text)
+	if fname == "where" {
+		if len(args) != 3 {
+			return nil, fmt.Errorf("where() expects 3 arguments, got %d", len(args))
+		}
+		return calcTernary{args[0], args[1], args[2]}, nil
+	}
+	fn, ok := calcFuncs[fname]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s at position %d in expression", name.text, name.pos)
+	}
+	if len(args) < fn.minArgs || (fn.maxArgs >= 0 && len(args) > fn.maxArgs) {
+		return nil, fmt.Errorf("invalid number of arguments (%d) for function %s", len(args), name.text)
+	}
+	return calcCall{fn.fn, args}, nil
+}
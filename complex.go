@@ -0,0 +1,146 @@
+// Copyright 2021 Airbus Defence and Space
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package godal
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// ComplexPart is the value extracted from complex pixels by the ComplexValues option
+type ComplexPart int
+
+const (
+	// RealPart extracts the real part of the complex values
+	RealPart ComplexPart = iota
+	// ImaginaryPart extracts the imaginary part of the complex values
+	ImaginaryPart
+	// Amplitude extracts the modulus of the complex values, i.e. sqrt(re²+im²)
+	Amplitude
+	// Phase extracts the argument of the complex values, in radians in [-π,π]
+	Phase
+	// Intensity extracts the squared modulus of the complex values, i.e. re²+im²
+	Intensity
+)
+
+// String implements Stringer
+func (cp ComplexPart) String() string {
+	switch cp {
+	case RealPart:
+		return "Real"
+	case ImaginaryPart:
+		return "Imaginary"
+	case Amplitude:
+		return "Amplitude"
+	case Phase:
+		return "Phase"
+	case Intensity:
+		return "Intensity"
+	default:
+		return fmt.Sprintf("ComplexPart(%d)", int(cp))
+	}
+}
+
+func (cp ComplexPart) extract(c complex128) float64 {
+	switch cp {
+	case ImaginaryPart:
+		return imag(c)
+	case Amplitude:
+		return cmplx.Abs(c)
+	case Phase:
+		return cmplx.Phase(c)
+	case Intensity:
+		return real(c)*real(c) + imag(c)*imag(c)
+	default:
+		return real(c)
+	}
+}
+
+// scaler converts raw pixel values to physical values, leaving nodata values untouched
+type scaler struct {
+	scale, offset float64
+	nodata        float64
+	hasNoData     bool
+}
+
+func (s scaler) isNoData(v float64) bool {
+	return s.hasNoData && (v == s.nodata || (math.IsNaN(v) && math.IsNaN(s.nodata)))
+}
+
+// apply scales the items of a float32 or float64 buffer. offset, pixelStride and lineStride
+// are expressed in number of items
+func (s scaler) apply(buffer interface{}, offset, pixelStride, lineStride, bufWidth, bufHeight int) error {
+	if s.scale == 1 && s.offset == 0 {
+		return nil
+	}
+	switch buf := buffer.(type) {
+	case []float32:
+		nd := float64(float32(s.nodata))
+		for y := 0; y < bufHeight; y++ {
+			for x := 0; x < bufWidth; x++ {
+				i := offset + y*lineStride + x*pixelStride
+				if v := float64(buf[i]); !s.hasNoData || v != nd {
+					buf[i] = float32(v*s.scale + s.offset)
+				}
+			}
+		}
+	case []float64:
+		for y := 0; y < bufHeight; y++ {
+			for x := 0; x < bufWidth; x++ {
+				i := offset + y*lineStride + x*pixelStride
+				if v := buf[i]; !s.isNoData(v) {
+					buf[i] = v*s.scale + s.offset
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("scale and offset can only be applied to []float32 or []float64 buffers")
+	}
+	return nil
+}
+
+// extractComplex stores the requested part of the packed complex values into a float32 or
+// float64 buffer, applying the scaler to the real and imaginary parts beforehand (the
+// offset being a real number, it only shifts the real part). Pixels
+// whose real part equals the nodata value are set to the nodata value. offset, pixelStride
+// and lineStride are expressed in number of items
+func extractComplex(src []complex128, part ComplexPart, s scaler, buffer interface{},
+	offset, pixelStride, lineStride, bufWidth, bufHeight int) error {
+	value := func(c complex128) float64 {
+		if s.isNoData(real(c)) {
+			return s.nodata
+		}
+		c = complex(real(c)*s.scale+s.offset, imag(c)*s.scale)
+		return part.extract(c)
+	}
+	switch buf := buffer.(type) {
+	case []float32:
+		for y := 0; y < bufHeight; y++ {
+			for x := 0; x < bufWidth; x++ {
+				buf[offset+y*lineStride+x*pixelStride] = float32(value(src[y*bufWidth+x]))
+			}
+		}
+	case []float64:
+		for y := 0; y < bufHeight; y++ {
+			for x := 0; x < bufWidth; x++ {
+				buf[offset+y*lineStride+x*pixelStride] = value(src[y*bufWidth+x])
+			}
+		}
+	default:
+		return fmt.Errorf("complex values can only be extracted to []float32 or []float64 buffers")
+	}
+	return nil
+}
@@ -14,6 +14,12 @@
 
 package godal
 
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
 //DriverName is GDAL driver
 type DriverName string
 
@@ -42,6 +48,42 @@ const (
 	Mitab DriverName = "Mitab"
 	//CSV comma-separated values driver
 	CSV DriverName = "CSV"
+	//COG is a Cloud Optimized GeoTIFF (creation only, reading is done by the GTiff driver)
+	COG DriverName = "COG"
+	//PNG is a Portable Network Graphics image
+	PNG DriverName = "PNG"
+	//JPEG is a JPEG JFIF image
+	JPEG DriverName = "JPEG"
+	//WEBP is a WEBP image
+	WEBP DriverName = "WEBP"
+	//NetCDF is a Network Common Data Format file
+	NetCDF DriverName = "netCDF"
+	//HDF5 is a Hierarchical Data Format Release 5 file
+	HDF5 DriverName = "HDF5"
+	//Zarr is a Zarr V2 or V3 dataset
+	Zarr DriverName = "Zarr"
+	//FlatGeobuf is a FlatGeobuf file
+	FlatGeobuf DriverName = "FlatGeobuf"
+	//Parquet is an (Geo)Parquet file
+	Parquet DriverName = "Parquet"
+	//GeoJSONSeq is a sequence of GeoJSON features
+	GeoJSONSeq DriverName = "GeoJSONSeq"
+	//MVT is a Mapbox Vector Tiles file or directory
+	MVT DriverName = "MVT"
+	//KML is a Keyhole Markup Language file
+	KML DriverName = "KML"
+	//GML is a Geography Markup Language file
+	GML DriverName = "GML"
+	//PMTiles is a ProtoMap Tiles file
+	PMTiles DriverName = "PMTiles"
+	//SQLite is a SQLite/Spatialite database
+	SQLite DriverName = "SQLite"
+	//ENVI is an ENVI .hdr labelled raster
+	ENVI DriverName = "ENVI"
+	//PostGISRaster is a PostGIS raster table
+	PostGISRaster DriverName = "PostGISRaster"
+	//ISIS3 is a USGS Astrogeology ISIS cube (version 3)
+	ISIS3 DriverName = "ISIS3"
 )
 
 type driverMapping struct {
@@ -106,6 +148,80 @@ var driverMappings = map[DriverName]driverMapping{
 		vectorName:     "CSV",
 		vectorRegister: "RegisterOGRCSV",
 	},
+	COG: {
+		rasterName:     "COG",
+		rasterRegister: "GDALRegister_COG",
+	},
+	PNG: {
+		rasterName:     "PNG",
+		rasterRegister: "GDALRegister_PNG",
+	},
+	JPEG: {
+		rasterName:     "JPEG",
+		rasterRegister: "GDALRegister_JPEG",
+	},
+	WEBP: {
+		rasterName:     "WEBP",
+		rasterRegister: "GDALRegister_WEBP",
+	},
+	NetCDF: {
+		rasterName:     "netCDF",
+		vectorName:     "netCDF",
+		rasterRegister: "GDALRegister_netCDF",
+		vectorRegister: "GDALRegister_netCDF",
+	},
+	HDF5: {
+		rasterName:     "HDF5",
+		rasterRegister: "GDALRegister_HDF5",
+	},
+	Zarr: {
+		rasterName:     "Zarr",
+		rasterRegister: "GDALRegister_Zarr",
+	},
+	FlatGeobuf: {
+		vectorName:     "FlatGeobuf",
+		vectorRegister: "RegisterOGRFlatGeobuf",
+	},
+	Parquet: {
+		vectorName:     "Parquet",
+		vectorRegister: "RegisterOGRParquet",
+	},
+	GeoJSONSeq: {
+		vectorName:     "GeoJSONSeq",
+		vectorRegister: "RegisterOGRGeoJSONSeq",
+	},
+	MVT: {
+		vectorName:     "MVT",
+		vectorRegister: "RegisterOGRMVT",
+	},
+	KML: {
+		vectorName:     "KML",
+		vectorRegister: "RegisterOGRKML",
+	},
+	GML: {
+		vectorName:     "GML",
+		vectorRegister: "RegisterOGRGML",
+	},
+	PMTiles: {
+		vectorName:     "PMTiles",
+		vectorRegister: "RegisterOGRPMTiles",
+	},
+	SQLite: {
+		vectorName:     "SQLite",
+		vectorRegister: "RegisterOGRSQLite",
+	},
+	ENVI: {
+		rasterName:     "ENVI",
+		rasterRegister: "GDALRegister_ENVI",
+	},
+	PostGISRaster: {
+		rasterName:     "PostGISRaster",
+		rasterRegister: "GDALRegister_PostGISRaster",
+	},
+	ISIS3: {
+		rasterName:     "ISIS3",
+		rasterRegister: "GDALRegister_ISIS3",
+	},
 }
 
 func (dn DriverName) setDatasetVectorTranslateOpt(to *dsVectorTranslateOpts) {
@@ -124,6 +240,14 @@ func (dn DriverName) setRasterizeOpt(to *rasterizeOpts) {
 	to.driver = dn
 }
 
+func (dn DriverName) setCalcOpt(co *calcOpts) {
+	co.driver = dn
+}
+
+func (dn DriverName) setDatasetFilesOpt(o *datasetFilesOpts) {
+	o.driver = dn
+}
+
 type driversOpt struct {
 	drivers []string
 }
@@ -156,3 +280,91 @@ func (doo driverOpenOption) setOpenOpt(oo *openOpts) {
 func (doo driverOpenOption) setBuildVRTOpt(bvo *buildVRTOpts) {
 	bvo.openOptions = append(bvo.openOptions, doo.oo...)
 }
+
+// IsRaster returns whether the driver supports raster data
+func (drv Driver) IsRaster() bool {
+	return drv.Metadata("DCAP_RASTER") == "YES"
+}
+
+// IsVector returns whether the driver supports vector data
+func (drv Driver) IsVector() bool {
+	return drv.Metadata("DCAP_VECTOR") == "YES"
+}
+
+// IsMultiDimensional returns whether the driver supports multidimensional raster data
+func (drv Driver) IsMultiDimensional() bool {
+	return drv.Metadata("DCAP_MULTIDIM_RASTER") == "YES"
+}
+
+// CanCreate returns whether the driver can create new datasets with Create()
+func (drv Driver) CanCreate() bool {
+	return drv.Metadata("DCAP_CREATE") == "YES"
+}
+
+// CanCreateCopy returns whether the driver can create new datasets with Dataset.CreateCopy()
+func (drv Driver) CanCreateCopy() bool {
+	return drv.Metadata("DCAP_CREATECOPY") == "YES"
+}
+
+// SupportsVirtualIO returns whether the driver supports reading from and writing to
+// /vsi virtual files
+func (drv Driver) SupportsVirtualIO() bool {
+	return drv.Metadata("DCAP_VIRTUALIO") == "YES"
+}
+
+// Extensions returns the file extensions handled by the driver, without the leading dot
+func (drv Driver) Extensions() []string {
+	return strings.Fields(drv.Metadata("DMD_EXTENSIONS"))
+}
+
+// DriverOption describes a creation or open option supported by a driver
+type DriverOption struct {
+	Name string `xml:"name,attr"`
+	// Type is one of "int", "float", "string", "string-select" or "boolean"
+	Type        string `xml:"type,attr"`
+	Description string `xml:"description,attr"`
+	Default     string `xml:"default,attr"`
+	// Min and Max are the bounds of numeric options, if any
+	Min string `xml:"min,attr"`
+	Max string `xml:"max,attr"`
+	// Scope is set to "raster" or "vector" for drivers supporting both, when the option
+	// only applies to one of them
+	Scope string `xml:"scope,attr"`
+	// Values are the allowed values of "string-select" options
+	Values []DriverOptionValue `xml:"Value"`
+}
+
+// DriverOptionValue is an allowed value of a "string-select" DriverOption
+type DriverOptionValue struct {
+	Value string `xml:",chardata"`
+	// Alias is an alternate name that can be used for this value, if any
+	Alias string `xml:"alias,attr"`
+}
+
+type driverOptionList struct {
+	Options []DriverOption `xml:"Option"`
+}
+
+func (drv Driver) parseOptionList(key string) ([]DriverOption, error) {
+	str := drv.Metadata(key)
+	if str == "" {
+		return nil, nil
+	}
+	ol := driverOptionList{}
+	if err := xml.Unmarshal([]byte(str), &ol); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", key, err)
+	}
+	return ol.Options, nil
+}
+
+// CreationOptions returns the creation options supported by the driver, as parsed from
+// its DMD_CREATIONOPTIONLIST metadata item
+func (drv Driver) CreationOptions() ([]DriverOption, error) {
+	return drv.parseOptionList("DMD_CREATIONOPTIONLIST")
+}
+
+// OpenOptions returns the open options supported by the driver, as parsed from its
+// DMD_OPENOPTIONLIST metadata item
+func (drv Driver) OpenOptions() ([]DriverOption, error) {
+	return drv.parseOptionList("DMD_OPENOPTIONLIST")
+}
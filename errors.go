@@ -133,21 +133,45 @@ func ErrLogger(fn ErrorHandler) interface {
 	SetFieldValueOption
 	SetNoDataOption
 	SetScaleOffsetOption
+	SetUnitOption
+	SetCategoryNamesOption
+	ComputeMinMaxOption
+	AttributeTableOption
+	SetAttributeTableOption
+	CreateCopyOption
+	DatasetFilesOption
+	ValidateCreationOptionsOption
 	SetGeoTransformOption
 	SetGeometryColumnNameOption
 	SetProjectionOption
 	SetSpatialRefOption
+	SetCoordinateEpochOption
+	PromoteTo3DOption
+	DemoteTo2DOption
+	ListCRSOption
+	FindMatchesOption
+	CloneGeogCSOption
+	StripVerticalOption
+	CloneTransformOption
+	LayerGeometriesOption
 	SieveFilterOption
 	SimplifyOption
 	SpatialRefValidateOption
 	SubGeometryOption
 	TransformOption
+	CoordinateOperationsOption
+	NewTransformerOption
+	TransformPointsOption
+	PixelGeoOption
+	WindowFromBoundsOption
 	UnionOption
 	UpdateFeatureOption
 	VSIHandlerOption
 	VSIOpenOption
 	VSIUnlinkOption
 	WKTExportOption
+	PROJJSONExportOption
+	Proj4ExportOption
 	StatisticsOption
 	SetStatisticsOption
 	ClearStatisticsOption
@@ -157,6 +181,10 @@ func ErrLogger(fn ErrorHandler) interface {
 	SetGCPsOption
 	GCPsToGeoTransformOption
 	RegisterPluginOption
+	CalcOption
+	DatasetAdviseReadOption
+	BandAdviseReadOption
+	PrefetchOption
 } {
 	return errorCallback{fn}
 }
@@ -320,6 +348,30 @@ func (ec errorCallback) setSetProjectionOpt(o *setProjectionOpts) {
 func (ec errorCallback) setSetNoDataOpt(ndo *setNodataOpts) {
 	ndo.errorHandler = ec.fn
 }
+func (ec errorCallback) setValidateCreationOptionsOpt(o *validateCreationOptionsOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setDatasetFilesOpt(o *datasetFilesOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setCreateCopyOpt(o *createCopyOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setAttributeTableOpt(ato *attributeTableOpts) {
+	ato.errorHandler = ec.fn
+}
+func (ec errorCallback) setSetAttributeTableOpt(sato *setAttributeTableOpts) {
+	sato.errorHandler = ec.fn
+}
+func (ec errorCallback) setSetCategoryNamesOpt(scno *setCategoryNamesOpts) {
+	scno.errorHandler = ec.fn
+}
+func (ec errorCallback) setComputeMinMaxOpt(cmo *computeMinMaxOpts) {
+	cmo.errorHandler = ec.fn
+}
+func (ec errorCallback) setSetUnitOpt(suo *setUnitOpts) {
+	suo.errorHandler = ec.fn
+}
 func (ec errorCallback) setSetScaleOffsetOpt(soo *setScaleOffsetOpts) {
 	soo.errorHandler = ec.fn
 }
@@ -338,9 +390,51 @@ func (ec errorCallback) setSpatialRefValidateOpt(o *spatialRefValidateOpts) {
 func (ec errorCallback) setSubGeometryOpt(so *subGeometryOpts) {
 	so.errorHandler = ec.fn
 }
+func (ec errorCallback) setSetCoordinateEpochOpt(o *setCoordinateEpochOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setPromoteTo3DOpt(o *promoteTo3DOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setDemoteTo2DOpt(o *demoteTo2DOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setListCRSOpt(o *listCRSOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setFindMatchesOpt(o *findMatchesOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setCloneGeogCSOpt(o *cloneGeogCSOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setStripVerticalOpt(o *stripVerticalOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setCloneTransformOpt(o *cloneTransformOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setLayerGeometriesOpt(o *layerGeometriesOpts) {
+	o.errorHandler = ec.fn
+}
 func (ec errorCallback) setTransformOpt(o *trnOpts) {
 	o.errorHandler = ec.fn
 }
+func (ec errorCallback) setCoordinateOperationsOpt(o *trnOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setNewTransformerOpt(o *newTransformerOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setTransformPointsOpt(o *transformPointsOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setPixelGeoOpt(o *pixelGeoOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setWindowFromBoundsOpt(o *windowFromBoundsOpts) {
+	o.errorHandler = ec.fn
+}
 func (ec errorCallback) setUnionOpt(uo *unionOpts) {
 	uo.errorHandler = ec.fn
 }
@@ -359,6 +453,12 @@ func (ec errorCallback) setVSIUnlinkOpt(o *vsiUnlinkOpts) {
 func (ec errorCallback) setWKTExportOpt(o *srWKTOpts) {
 	o.errorHandler = ec.fn
 }
+func (ec errorCallback) setPROJJSONExportOpt(o *srPROJJSONOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setProj4ExportOpt(o *srProj4Opts) {
+	o.errorHandler = ec.fn
+}
 
 func (ec errorCallback) setStatisticsOpt(o *statisticsOpts) {
 	o.errorHandler = ec.fn
@@ -392,6 +492,18 @@ func (ec errorCallback) setGCPsToGeoTransformOpts(o *gcpsToGeoTransformOpts) {
 func (ec errorCallback) setRegisterPluginOpt(o *registerPluginOpts) {
 	o.errorHandler = ec.fn
 }
+func (ec errorCallback) setCalcOpt(o *calcOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setDatasetAdviseReadOpt(o *adviseReadOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setBandAdviseReadOpt(o *adviseReadOpts) {
+	o.errorHandler = ec.fn
+}
+func (ec errorCallback) setPrefetchOpt(o *prefetchOpts) {
+	o.errorHandler = ec.fn
+}
 
 type multiError struct {
 	errs []error
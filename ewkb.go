@@ -0,0 +1,224 @@
+// Copyright 2021 Airbus Defence and Space
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package godal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/airbusgeo/godal/ewkb"
+)
+
+// srid returns the EPSG code of the SpatialRef, or 0 if it cannot be determined. SpatialRefs
+// that are not explicitly identified by an EPSG authority code are matched against the EPSG
+// database, in which case only an exact and unambiguous match is used.
+func srid(sr *SpatialRef) int {
+	if sr == nil || sr.handle == nil {
+		return 0
+	}
+	if strings.EqualFold(sr.AuthorityName(""), "EPSG") {
+		code, err := strconv.Atoi(sr.AuthorityCode(""))
+		if err != nil || code < 0 {
+			return 0
+		}
+		return code
+	}
+	matches, err := sr.FindMatches(Authority("EPSG"))
+	if err != nil || len(matches) == 0 || matches[0].Confidence < 100 ||
+		(len(matches) > 1 && matches[1].Confidence >= 100) {
+		return 0
+	}
+	code, err := strconv.Atoi(matches[0].Code)
+	if err != nil || code < 0 {
+		return 0
+	}
+	return code
+}
+
+func (g *Geometry) ewkb(eh ErrorHandler, srid int) ([]byte, error) {
+	var wopts []GeometryWKBOption
+	if eh != nil {
+		wopts = append(wopts, ErrLogger(eh))
+	}
+	wkb, err := g.WKB(wopts...)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := ewkb.FromWKB(wkb, srid)
+	if err != nil {
+		return nil, fmt.Errorf("convert %s: %w", g.Name(), err)
+	}
+	return ret, nil
+}
+
+// EWKB returns the extended WKB representation of the Geometry, as used by PostGIS and
+// decoded by most Go geometry libraries. Z and M values are preserved, and the SRID is
+// set to the EPSG code of the Geometry's SpatialRef. It is left out if the SpatialRef is
+// not identified by an EPSG authority code and does not exactly match a single EPSG CRS.
+func (g *Geometry) EWKB(opts ...GeometryWKBOption) ([]byte, error) {
+	wo := geometryWKBOpts{}
+	for _, o := range opts {
+		o.setGeometryWKBOpt(&wo)
+	}
+	return g.ewkb(wo.errorHandler, srid(g.SpatialRef()))
+}
+
+// NewGeometryFromEWKB creates a new Geometry from its extended WKB representation. Z and
+// M values are preserved, and the SpatialRef of the returned Geometry is set from the
+// SRID, if any, as an EPSG code.
+func NewGeometryFromEWKB(b []byte, opts ...NewGeometryOption) (*Geometry, error) {
+	no := newGeometryOpts{}
+	for _, o := range opts {
+		o.setNewGeometryOpt(&no)
+	}
+	wkb, code, err := ewkb.ToWKB(b)
+	if err != nil {
+		return nil, err
+	}
+	var sr *SpatialRef
+	if code != 0 {
+		var sopts []CreateSpatialRefOption
+		if no.errorHandler != nil {
+			sopts = append(sopts, ErrLogger(no.errorHandler))
+		}
+		if sr, err = NewSpatialRefFromEPSG(code, sopts...); err != nil {
+			return nil, err
+		}
+		defer sr.Close()
+	}
+	return NewGeometryFromWKB(wkb, sr, opts...)
+}
+
+// forEachGeometry calls fn with the FID and the Geometry of each feature of the Layer, in
+// the order returned by Layer.NextFeature. The Geometry is nil for features without one,
+// and must not be used once fn has returned.
+func (layer Layer) forEachGeometry(fn func(fid int64, g *Geometry) error) error {
+	layer.ResetReading()
+	for {
+		feat := layer.NextFeature()
+		if feat == nil {
+			return nil
+		}
+		g := feat.Geometry()
+		if g.handle == nil {
+			g = nil
+		}
+		err := fn(feat.FID(), g)
+		feat.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ForEachEWKB calls fn with the FID and the extended WKB representation (see Geometry.EWKB)
+// of the geometry of each feature of the Layer, in the order returned by Layer.NextFeature.
+// Features are read one at a time, so that the whole Layer is never held in memory. The
+// EWKB is nil for features without a geometry. The SRID of the geometries is determined
+// once from the Layer's SpatialRef.
+//
+// ForEachEWKB resets the reading of the Layer before iterating over its features. It stops
+// and returns the error returned by fn if not nil.
+func (layer Layer) ForEachEWKB(fn func(fid int64, ewkb []byte) error, opts ...LayerGeometriesOption) error {
+	lgo := layerGeometriesOpts{}
+	for _, o := range opts {
+		o.setLayerGeometriesOpt(&lgo)
+	}
+	code := layer.srid()
+	return layer.forEachGeometry(func(fid int64, g *Geometry) error {
+		if g == nil {
+			return fn(fid, nil)
+		}
+		b, err := g.ewkb(lgo.errorHandler, code)
+		if err != nil {
+			return fmt.Errorf("feature %d: %w", fid, err)
+		}
+		return fn(fid, b)
+	})
+}
+
+// srid returns the EPSG code of the Layer's SpatialRef, or 0 if it cannot be determined
+func (layer Layer) srid() int {
+	sr := layer.SpatialRef()
+	defer sr.Close()
+	return srid(sr)
+}
+
+// newFeatures creates n features in the Layer, the i-th one having the ISO WKB geometry
+// returned by wkb(i), and returns their FIDs. SRIDs other than 0 must match the EPSG code
+// of the Layer, if it can be determined.
+func (layer Layer) newFeatures(n int, eh ErrorHandler, wkb func(i int) ([]byte, int, error)) ([]int64, error) {
+	var gopts []NewGeometryOption
+	var fopts []NewFeatureOption
+	var copts []CreateFeatureOption
+	if eh != nil {
+		gopts = append(gopts, ErrLogger(eh))
+		fopts = append(fopts, ErrLogger(eh))
+		copts = append(copts, ErrLogger(eh))
+	}
+	code := layer.srid()
+	fids := make([]int64, 0, n)
+	for i := 0; i < n; i++ {
+		b, gsrid, err := wkb(i)
+		if err != nil {
+			return fids, fmt.Errorf("geometry %d: %w", i, err)
+		}
+		if gsrid != 0 && code != 0 && gsrid != code {
+			return fids, fmt.Errorf("geometry %d: srid %d does not match the layer's EPSG:%d", i, gsrid, code)
+		}
+		var feat *Feature
+		if b == nil {
+			// NewFeature does not add features without a geometry to the Layer
+			if feat, err = layer.NewFeature(nil, fopts...); err == nil {
+				if err = layer.CreateFeature(feat, copts...); err != nil {
+					feat.Close()
+				}
+			}
+		} else {
+			var g *Geometry
+			if g, err = NewGeometryFromWKB(b, nil, gopts...); err == nil {
+				feat, err = layer.NewFeature(g, fopts...)
+				g.Close()
+			}
+		}
+		if err != nil {
+			return fids, fmt.Errorf("geometry %d: %w", i, err)
+		}
+		fids = append(fids, feat.FID())
+		feat.Close()
+	}
+	return fids, nil
+}
+
+// NewFeaturesFromEWKB creates a feature in the Layer for each of the given extended WKB
+// geometries (see NewGeometryFromEWKB), and returns the FIDs of the created features. Nil
+// entries create features without a geometry. Geometries are written as is: an error is
+// returned for SRIDs that differ from the EPSG code of the Layer's SpatialRef, geometries
+// without SRID being assumed to be in the Layer's SpatialRef.
+//
+// If an error occurs, the FIDs of the features created so far are returned along with it.
+func (layer Layer) NewFeaturesFromEWKB(ewkbs [][]byte, opts ...LayerGeometriesOption) ([]int64, error) {
+	lgo := layerGeometriesOpts{}
+	for _, o := range opts {
+		o.setLayerGeometriesOpt(&lgo)
+	}
+	return layer.newFeatures(len(ewkbs), lgo.errorHandler, func(i int) ([]byte, int, error) {
+		if ewkbs[i] == nil {
+			return nil, 0, nil
+		}
+		return ewkb.ToWKB(ewkbs[i])
+	})
+}
@@ -0,0 +1,290 @@
+// Copyright 2021 Airbus Defence and Space
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ewkb converts geometries between the ISO WKB encoding used by GDAL and the
+// extended WKB (EWKB) encoding used by PostGIS, which carries the SRID of the geometry.
+// It does not depend on GDAL.
+//
+// EWKB is understood by the most common Go geometry libraries (e.g. the ewkb packages of
+// orb and go-geom, or simplefeatures), and is the format produced by
+// godal.Geometry.EWKB() and godal.Layer.ForEachEWKB(), and consumed by
+// godal.NewGeometryFromEWKB() and godal.Layer.NewFeaturesFromEWKB().
+package ewkb
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	wkbPoint              = 1
+	wkbLineString         = 2
+	wkbPolygon            = 3
+	wkbMultiPoint         = 4
+	wkbMultiLineString    = 5
+	wkbMultiPolygon       = 6
+	wkbGeometryCollection = 7
+	wkbCircularString     = 8
+	wkbCompoundCurve      = 9
+	wkbCurvePolygon       = 10
+	wkbMultiCurve         = 11
+	wkbMultiSurface       = 12
+	wkbPolyhedralSurface  = 15
+	wkbTIN                = 16
+	wkbTriangle           = 17
+
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
+// header is the decoded type of a geometry
+type header struct {
+	gtype      uint32
+	hasZ, hasM bool
+	srid       int
+}
+
+func (h header) stride() int {
+	stride := 2
+	if h.hasZ {
+		stride++
+	}
+	if h.hasM {
+		stride++
+	}
+	return stride
+}
+
+type reader struct {
+	buf   []byte
+	order binary.ByteOrder
+}
+
+func (r *reader) uint32() (uint32, error) {
+	if len(r.buf) < 4 {
+		return 0, fmt.Errorf("truncated wkb")
+	}
+	v := r.order.Uint32(r.buf)
+	r.buf = r.buf[4:]
+	return v, nil
+}
+
+// count reads a number of elements, each of which takes at least minSize bytes. This
+// prevents huge allocations and loops on corrupted input.
+func (r *reader) count(minSize int) (int, error) {
+	n, err := r.uint32()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(n)*uint64(minSize) > uint64(len(r.buf)) {
+		return 0, fmt.Errorf("truncated wkb")
+	}
+	return int(n), nil
+}
+
+// header decodes an ISO, extended or OGC 2.5D geometry type, in either byte order
+func (r *reader) header() (header, error) {
+	if len(r.buf) < 1 {
+		return header{}, fmt.Errorf("truncated wkb")
+	}
+	switch r.buf[0] {
+	case 0:
+		r.order = binary.BigEndian
+	case 1:
+		r.order = binary.LittleEndian
+	default:
+		return header{}, fmt.Errorf("invalid wkb byte order %d", r.buf[0])
+	}
+	r.buf = r.buf[1:]
+	gtype, err := r.uint32()
+	if err != nil {
+		return header{}, err
+	}
+	h := header{}
+	if gtype&(ewkbZ|ewkbM|ewkbSRID) != 0 {
+		h.hasZ = gtype&ewkbZ != 0
+		h.hasM = gtype&ewkbM != 0
+		if gtype&ewkbSRID != 0 {
+			srid, err := r.uint32()
+			if err != nil {
+				return header{}, err
+			}
+			h.srid = int(srid)
+		}
+		gtype &= 0x0fffffff
+	}
+	switch gtype / 1000 {
+	case 1:
+		h.hasZ = true
+	case 2:
+		h.hasM = true
+	case 3:
+		h.hasZ, h.hasM = true, true
+	}
+	h.gtype = gtype % 1000
+	return h, nil
+}
+
+type writer struct {
+	buf  []byte
+	ewkb bool
+	// srid replaces the one of the top-level geometry if setSRID is true
+	srid    int
+	setSRID bool
+}
+
+func (w *writer) uint32(v uint32) {
+	w.buf = append(w.buf, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(w.buf[len(w.buf)-4:], v)
+}
+
+func (w *writer) header(h header, srid int) {
+	w.buf = append(w.buf, 1) //little endian
+	gtype := h.gtype
+	if !w.ewkb {
+		if h.hasZ {
+			gtype += 1000
+		}
+		if h.hasM {
+			gtype += 2000
+		}
+		w.uint32(gtype)
+		return
+	}
+	if h.hasZ {
+		gtype |= ewkbZ
+	}
+	if h.hasM {
+		gtype |= ewkbM
+	}
+	if srid != 0 {
+		gtype |= ewkbSRID
+	}
+	w.uint32(gtype)
+	if srid != 0 {
+		w.uint32(uint32(srid))
+	}
+}
+
+// coords copies n coordinates of the given stride, converting them to little endian
+func (w *writer) coords(r *reader, n, stride int) error {
+	size := n * stride * 8
+	if len(r.buf) < size {
+		return fmt.Errorf("truncated wkb")
+	}
+	for i := 0; i < size; i += 8 {
+		w.buf = append(w.buf, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.LittleEndian.PutUint64(w.buf[len(w.buf)-8:], r.order.Uint64(r.buf[i:]))
+	}
+	r.buf = r.buf[size:]
+	return nil
+}
+
+// points copies a counted sequence of coordinates, as found in linestrings and rings
+func (w *writer) points(r *reader, stride int) error {
+	n, err := r.count(stride * 8)
+	if err != nil {
+		return err
+	}
+	w.uint32(uint32(n))
+	return w.coords(r, n, stride)
+}
+
+// geometry re-encodes the geometry read from r and returns its srid. The srid is only
+// written for the top-level geometry, the members of collections never carrying one.
+func (w *writer) geometry(r *reader, top bool) (int, error) {
+	h, err := r.header()
+	if err != nil {
+		return 0, err
+	}
+	srid := 0
+	if top {
+		srid = h.srid
+		if w.setSRID {
+			srid = w.srid
+		}
+	}
+	w.header(h, srid)
+	stride := h.stride()
+	switch h.gtype {
+	case wkbPoint:
+		err = w.coords(r, 1, stride)
+	case wkbLineString, wkbCircularString:
+		err = w.points(r, stride)
+	case wkbPolygon, wkbTriangle:
+		// each ring takes at least the 4 bytes of its point count
+		var nrings int
+		if nrings, err = r.count(4); err != nil {
+			return 0, err
+		}
+		w.uint32(uint32(nrings))
+		for i := 0; i < nrings && err == nil; i++ {
+			err = w.points(r, stride)
+		}
+	case wkbMultiPoint, wkbMultiLineString, wkbMultiPolygon, wkbGeometryCollection,
+		wkbCompoundCurve, wkbCurvePolygon, wkbMultiCurve, wkbMultiSurface,
+		wkbPolyhedralSurface, wkbTIN:
+		// each member takes at least a byte order and a type
+		var n int
+		if n, err = r.count(5); err != nil {
+			return 0, err
+		}
+		w.uint32(uint32(n))
+		for i := 0; i < n && err == nil; i++ {
+			_, err = w.geometry(r, false)
+		}
+	default:
+		return 0, fmt.Errorf("unsupported wkb geometry type %d", h.gtype)
+	}
+	return h.srid, err
+}
+
+func convert(b []byte, w *writer) ([]byte, int, error) {
+	r := &reader{buf: b}
+	w.buf = make([]byte, 0, len(b)+4)
+	srid, err := w.geometry(r, true)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(r.buf) > 0 {
+		return nil, 0, fmt.Errorf("%d trailing bytes after wkb geometry", len(r.buf))
+	}
+	return w.buf, srid, nil
+}
+
+// FromWKB converts a WKB geometry to EWKB, tagging it with srid (usually an EPSG code) if
+// not 0. The input may be ISO WKB (as returned by godal.Geometry.WKB()), OGC 2.5D WKB or
+// EWKB, in which case its SRID is replaced. The output is in little endian byte order.
+func FromWKB(wkb []byte, srid int) ([]byte, error) {
+	if srid < 0 {
+		return nil, fmt.Errorf("invalid srid %d", srid)
+	}
+	ret, _, err := convert(wkb, &writer{ewkb: true, srid: srid, setSRID: true})
+	return ret, err
+}
+
+// ToWKB converts an EWKB geometry to ISO WKB, in little endian byte order, as expected by
+// godal.NewGeometryFromWKB(). It also returns the SRID of the geometry, or 0 if it has none.
+func ToWKB(ewkb []byte) ([]byte, int, error) {
+	return convert(ewkb, &writer{})
+}
+
+// SRID returns the SRID of an EWKB geometry, or 0 if it has none. Only the header of the
+// geometry is decoded.
+func SRID(ewkb []byte) (int, error) {
+	r := &reader{buf: ewkb}
+	h, err := r.header()
+	return h.srid, err
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 Airbus Defence and Space
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ewkb
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	pt := "0101000000" + "000000000000f03f" + "0000000000000040"
+	ptz := "01e9030000" + "000000000000f03f" + "0000000000000040" + "0000000000000840"
+	for _, tc := range []struct {
+		name string
+		wkb  string
+		srid int
+		ewkb string
+	}{
+		{"point", pt, 0, pt},
+		{"point srid", pt, 4326, "0101000020e6100000" + pt[10:]},
+		{"point z", ptz, 4326, "01010000a0e6100000" + ptz[10:]},
+		{"point m", "01d1070000" + ptz[10:], 0, "0101000040" + ptz[10:]},
+		{"point zm", "01b90b0000" + ptz[10:] + "0000000000001040", 0, "01010000c0" + ptz[10:] + "0000000000001040"},
+		{"empty linestring", "010200000000000000", 0, "010200000000000000"},
+		{"empty polygon", "010300000000000000", 0, "010300000000000000"},
+		{"polygon with an empty ring", "01030000000100000000000000", 3857, "0103000020110f00000100000000000000"},
+		{"multipoint z", "01ec03000001000000" + ptz, 32631,
+			"01040000a0777f000001000000" + "0101000080" + ptz[10:]},
+		{"collection", "010700000002000000" + pt + "010700000000000000", 0,
+			"010700000002000000" + pt + "010700000000000000"},
+		{"circularstring", "01080000000100000000000000000000000000000000000000", 0,
+			"01080000000100000000000000000000000000000000000000"},
+	} {
+		wkb, _ := hex.DecodeString(tc.wkb)
+		ewkb, err := FromWKB(wkb, tc.srid)
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, tc.ewkb, hex.EncodeToString(ewkb), tc.name)
+		srid, err := SRID(ewkb)
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, tc.srid, srid, tc.name)
+		back, srid, err := ToWKB(ewkb)
+		require.NoError(t, err, tc.name)
+		assert.Equal(t, tc.srid, srid, tc.name)
+		assert.Equal(t, tc.wkb, hex.EncodeToString(back), tc.name)
+	}
+
+	//big endian OGC 2.5D
+	be, _ := hex.DecodeString("0080000001" + "3ff0000000000000" + "4000000000000000" + "4008000000000000")
+	wkb, srid, err := ToWKB(be)
+	require.NoError(t, err)
+	assert.Equal(t, 0, srid)
+	assert.Equal(t, ptz, hex.EncodeToString(wkb))
+
+	//srid replacement
+	ewkb, _ := hex.DecodeString("0101000020e6100000" + pt[10:])
+	ewkb, err = FromWKB(ewkb, 0)
+	require.NoError(t, err)
+	assert.Equal(t, pt, hex.EncodeToString(ewkb))
+	_, err = FromWKB(ewkb, -1)
+	assert.Error(t, err)
+
+	ptb, _ := hex.DecodeString(pt)
+	_, _, err = ToWKB(ptb[:len(ptb)-1])
+	assert.Error(t, err)
+	_, _, err = ToWKB(append(ptb, 0))
+	assert.Error(t, err)
+	_, _, err = ToWKB(nil)
+	assert.Error(t, err)
+	_, err = SRID(nil)
+	assert.Error(t, err)
+	_, _, err = ToWKB([]byte{2, 1, 0, 0, 0})
+	assert.Error(t, err)
+	_, _, err = ToWKB([]byte{1, 0x20, 0, 0, 0x20})
+	assert.Error(t, err)
+	_, _, err = ToWKB([]byte{1, 99, 0, 0, 0})
+	assert.Error(t, err)
+	//huge counts on a short buffer
+	_, _, err = ToWKB([]byte{1, 2, 0, 0, 0, 0xff, 0xff, 0xff, 0x7f})
+	assert.Error(t, err)
+	_, _, err = ToWKB([]byte{1, 3, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0})
+	assert.Error(t, err)
+	_, _, err = ToWKB([]byte{1, 7, 0, 0, 0, 1, 0, 0, 0})
+	assert.Error(t, err)
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 Airbus Defence and Space
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package godal
+
+import (
+	"fmt"
+
+	"github.com/airbusgeo/godal/geom"
+)
+
+func (g *Geometry) geom(eh ErrorHandler, srid int) (geom.Geometry, error) {
+	var wopts []GeometryWKBOption
+	if eh != nil {
+		wopts = append(wopts, ErrLogger(eh))
+	}
+	wkb, err := g.WKB(wopts...)
+	if err != nil {
+		return nil, err
+	}
+	gg, err := geom.UnmarshalWKB(wkb)
+	if err != nil {
+		return nil, fmt.Errorf("convert %s: %w", g.Name(), err)
+	}
+	gg.SetSRID(srid)
+	return gg, nil
+}
+
+// Geom converts the Geometry to its pure Go geom.Geometry counterpart. Z and M values are
+// preserved, and the SRID is set as for Geometry.EWKB. Curve geometries are not supported.
+func (g *Geometry) Geom(opts ...GeometryWKBOption) (geom.Geometry, error) {
+	wo := geometryWKBOpts{}
+	for _, o := range opts {
+		o.setGeometryWKBOpt(&wo)
+	}
+	return g.geom(wo.errorHandler, srid(g.SpatialRef()))
+}
+
+// NewGeometryFromGeom creates a new Geometry from a pure Go geom.Geometry. Z and M values
+// are preserved, and the SpatialRef of the returned Geometry is set from the SRID, if not 0,
+// as an EPSG code.
+func NewGeometryFromGeom(g geom.Geometry, opts ...NewGeometryOption) (*Geometry, error) {
+	b, err := geom.MarshalEWKB(g)
+	if err != nil {
+		return nil, err
+	}
+	return NewGeometryFromEWKB(b, opts...)
+}
+
+// ForEachGeom calls fn with the FID and the pure Go geom.Geometry (see Geometry.Geom) of
+// each feature of the Layer, in the order returned by Layer.NextFeature. Features are read
+// one at a time, so that the whole Layer is never held in memory. The geom.Geometry is nil
+// for features without a geometry. The SRID of the geometries is determined once from the
+// Layer's SpatialRef.
+//
+// ForEachGeom resets the reading of the Layer before iterating over its features. It stops
+// and returns the error returned by fn if not nil.
+func (layer Layer) ForEachGeom(fn func(fid int64, g geom.Geometry) error, opts ...LayerGeometriesOption) error {
+	lgo := layerGeometriesOpts{}
+	for _, o := range opts {
+		o.setLayerGeometriesOpt(&lgo)
+	}
+	code := layer.srid()
+	return layer.forEachGeometry(func(fid int64, g *Geometry) error {
+		if g == nil {
+			return fn(fid, nil)
+		}
+		gg, err := g.geom(lgo.errorHandler, code)
+		if err != nil {
+			return fmt.Errorf("feature %d: %w", fid, err)
+		}
+		return fn(fid, gg)
+	})
+}
+
+// NewFeaturesFromGeoms creates a feature in the Layer for each of the given pure Go
+// geometries, and returns the FIDs of the created features. Nil entries create features
+// without a geometry. Geometries are written as is: an error is returned for SRIDs that
+// differ from the EPSG code of the Layer's SpatialRef, geometries without SRID being
+// assumed to be in the Layer's SpatialRef.
+//
+// If an error occurs, the FIDs of the features created so far are returned along with it.
+func (layer Layer) NewFeaturesFromGeoms(geoms []geom.Geometry, opts ...LayerGeometriesOption) ([]int64, error) {
+	lgo := layerGeometriesOpts{}
+	for _, o := range opts {
+		o.setLayerGeometriesOpt(&lgo)
+	}
+	return layer.newFeatures(len(geoms), lgo.errorHandler, func(i int) ([]byte, int, error) {
+		if geoms[i] == nil {
+			return nil, 0, nil
+		}
+		b, err := geom.MarshalWKB(geoms[i])
+		return b, geoms[i].GeometryHeader().SRID, err
+	})
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 Airbus Defence and Space
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package geom is a lightweight, pure Go representation of simple feature geometries,
+// that does not depend on GDAL. Coordinates are stored in flat slices whose stride depends
+// on the geometry Layout, so that Z and M values are preserved.
+//
+// Geometries can be converted from and to godal Geometries with godal.Geometry.Geom(),
+// godal.NewGeometryFromGeom(), godal.Layer.ForEachGeom() and
+// godal.Layer.NewFeaturesFromGeoms(), and from and to other Go geometry libraries (e.g.
+// orb, go-geom, simplefeatures) through their WKB or EWKB codecs, using MarshalWKB,
+// MarshalEWKB and UnmarshalWKB.
+package geom
+
+import "fmt"
+
+// Layout describes the dimensions of the coordinates of a Geometry
+type Layout int
+
+const (
+	// XY is the layout of 2D coordinates
+	XY Layout = iota
+	// XYZ is the layout of 3D coordinates
+	XYZ
+	// XYM is the layout of 2D coordinates with a measure
+	XYM
+	// XYZM is the layout of 3D coordinates with a measure
+	XYZM
+)
+
+// Stride returns the number of values of each coordinate
+func (l Layout) Stride() int {
+	switch l {
+	case XYZ, XYM:
+		return 3
+	case XYZM:
+		return 4
+	default:
+		return 2
+	}
+}
+
+// HasZ returns whether the layout has a Z dimension
+func (l Layout) HasZ() bool {
+	return l == XYZ || l == XYZM
+}
+
+// HasM returns whether the layout has a M dimension
+func (l Layout) HasM() bool {
+	return l == XYM || l == XYZM
+}
+
+// String implements Stringer
+func (l Layout) String() string {
+	switch l {
+	case XY:
+		return "XY"
+	case XYZ:
+		return "XYZ"
+	case XYM:
+		return "XYM"
+	case XYZM:
+		return "XYZM"
+	default:
+		return fmt.Sprintf("Layout(%d)", int(l))
+	}
+}
+
+// Header holds the properties common to all geometries
+type Header struct {
+	Layout Layout
+	// SRID is the EPSG code of the spatial reference of the geometry, or 0 if unknown
+	SRID int
+}
+
+// GeometryHeader returns the Header of the geometry
+func (h Header) GeometryHeader() Header {
+	return h
+}
+
+// SetSRID sets the SRID of the geometry
+func (h *Header) SetSRID(srid int) {
+	h.SRID = srid
+}
+
+// Geometry is implemented by *Point, *LineString, *Polygon, *MultiPoint, *MultiLineString,
+// *MultiPolygon and *GeometryCollection
+type Geometry interface {
+	GeometryHeader() Header
+	SetSRID(srid int)
+}
+
+// Point is a single position. An empty Point has nil Coords.
+type Point struct {
+	Header
+	// Coords holds a single coordinate of Layout.Stride() values
+	Coords []float64
+}
+
+// LineString is a sequence of positions
+type LineString struct {
+	Header
+	// Coords holds the flattened coordinates, i.e. x0,y0,x1,y1,... for the XY Layout
+	Coords []float64
+}
+
+// Polygon is a surface delimited by an exterior ring and optional interior rings
+type Polygon struct {
+	Header
+	// Rings holds the flattened coordinates of each ring, the first one being the
+	// exterior ring
+	Rings [][]float64
+}
+
+// MultiPoint is a collection of Points. Empty points are represented by nil coordinates.
+type MultiPoint struct {
+	Header
+	// Points holds the coordinates of each Point
+	Points [][]float64
+}
+
+// MultiLineString is a collection of LineStrings
+type MultiLineString struct {
+	Header
+	// Lines holds the flattened coordinates of each LineString
+	Lines [][]float64
+}
+
+// MultiPolygon is a collection of Polygons
+type MultiPolygon struct {
+	Header
+	// Polygons holds the flattened coordinates of the rings of each Polygon
+	Polygons [][][]float64
+}
+
+// GeometryCollection is a collection of geometries of any type. When encoding, the Layout
+// and SRID of the members are ignored in favor of the ones of the collection.
+type GeometryCollection struct {
+	Header
+	Geometries []Geometry
+}
+
+// SetSRID sets the SRID of the collection and of its members
+func (gc *GeometryCollection) SetSRID(srid int) {
+	gc.SRID = srid
+	for _, g := range gc.Geometries {
+		if g != nil {
+			g.SetSRID(srid)
+		}
+	}
+}
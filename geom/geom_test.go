@@ -0,0 +1,136 @@
+// Copyright 2021 Airbus Defence and Space
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geom
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayout(t *testing.T) {
+	assert.Equal(t, 2, XY.Stride())
+	assert.Equal(t, 3, XYZ.Stride())
+	assert.Equal(t, 3, XYM.Stride())
+	assert.Equal(t, 4, XYZM.Stride())
+	assert.True(t, XYZM.HasZ())
+	assert.True(t, XYZM.HasM())
+	assert.False(t, XYM.HasZ())
+	assert.False(t, XYZ.HasM())
+	assert.Equal(t, "XYM", XYM.String())
+	assert.Equal(t, "Layout(9)", Layout(9).String())
+}
+
+func TestWKB(t *testing.T) {
+	pt := &Point{Header: Header{Layout: XY}, Coords: []float64{1, 2}}
+	wkb, err := MarshalWKB(pt)
+	require.NoError(t, err)
+	assert.Equal(t, "0101000000000000000000f03f0000000000000040", hex.EncodeToString(wkb))
+
+	ptz := &Point{Header: Header{Layout: XYZ, SRID: 4326}, Coords: []float64{1, 2, 3}}
+	wkb, _ = MarshalWKB(ptz)
+	assert.Equal(t, "01e9030000", hex.EncodeToString(wkb[0:5]))
+	ewkb, err := MarshalEWKB(ptz)
+	require.NoError(t, err)
+	assert.Equal(t, "01010000a0e6100000", hex.EncodeToString(ewkb[0:9]))
+	g, err := UnmarshalWKB(ewkb)
+	require.NoError(t, err)
+	assert.Equal(t, ptz, g)
+	g, _ = UnmarshalWKB(wkb)
+	assert.Equal(t, Header{Layout: XYZ}, g.GeometryHeader())
+
+	//big endian OGC 2.5D
+	be, _ := hex.DecodeString("0080000001" + "3ff0000000000000" + "4000000000000000" + "4008000000000000")
+	g, err = UnmarshalWKB(be)
+	require.NoError(t, err)
+	assert.Equal(t, &Point{Header: Header{Layout: XYZ}, Coords: []float64{1, 2, 3}}, g)
+
+	geoms := []Geometry{
+		&Point{Header: Header{Layout: XYM}},
+		&LineString{Header: Header{Layout: XYM}, Coords: []float64{0, 0, 1, 1, 1, 2}},
+		&Polygon{Header: Header{Layout: XYZM}, Rings: [][]float64{
+			{0, 0, 1, 9, 1, 0, 2, 9, 1, 1, 3, 9, 0, 0, 1, 9},
+			{0.1, 0.1, 1, 8, 0.2, 0.1, 1, 8, 0.2, 0.2, 1, 8, 0.1, 0.1, 1, 8},
+		}},
+		&Polygon{Header: Header{Layout: XY}, Rings: [][]float64{{}}},
+		&Polygon{Header: Header{Layout: XY}, Rings: [][]float64{}},
+		&MultiPoint{Header: Header{Layout: XY}, Points: [][]float64{{0, 0}, nil, {1, 1}}},
+		&MultiLineString{Header: Header{Layout: XYZ}, Lines: [][]float64{{0, 0, 0, 1, 1, 1}, {}}},
+		&MultiPolygon{Header: Header{Layout: XY}, Polygons: [][][]float64{
+			{{0, 0, 1, 0, 1, 1, 0, 0}},
+			{},
+		}},
+		&GeometryCollection{Header: Header{Layout: XYZ}, Geometries: []Geometry{
+			&Point{Header: Header{Layout: XYZ}, Coords: []float64{1, 2, 3}},
+			&GeometryCollection{Header: Header{Layout: XYZ}, Geometries: []Geometry{}},
+		}},
+	}
+	for _, g := range geoms {
+		wkb, err := MarshalWKB(g)
+		require.NoError(t, err)
+		dec, err := UnmarshalWKB(wkb)
+		require.NoError(t, err)
+		assert.Equal(t, g, dec)
+
+		g.SetSRID(32631)
+		ewkb, err := MarshalEWKB(g)
+		require.NoError(t, err)
+		dec, err = UnmarshalWKB(ewkb)
+		require.NoError(t, err)
+		assert.Equal(t, g, dec)
+	}
+
+	_, err = MarshalWKB(nil)
+	assert.Error(t, err)
+	_, err = MarshalEWKB(&LineString{Header: Header{Layout: XYZ}, Coords: []float64{0, 0}})
+	assert.Error(t, err)
+	_, err = MarshalWKB(&Point{Coords: []float64{0, 0, 0}})
+	assert.Error(t, err)
+	_, err = MarshalWKB(&GeometryCollection{Geometries: []Geometry{nil}})
+	assert.Error(t, err)
+
+	wkb, _ = MarshalWKB(geoms[2])
+	_, err = UnmarshalWKB(wkb[0 : len(wkb)-1])
+	assert.Error(t, err)
+	_, err = UnmarshalWKB(append(wkb, 0))
+	assert.Error(t, err)
+	_, err = UnmarshalWKB(nil)
+	assert.Error(t, err)
+	_, err = UnmarshalWKB([]byte{2, 1, 0, 0, 0})
+	assert.Error(t, err)
+	circ, _ := hex.DecodeString("010800000000000000")
+	_, err = UnmarshalWKB(circ)
+	assert.Error(t, err)
+	//huge counts on a short buffer
+	_, err = UnmarshalWKB([]byte{1, 2, 0, 0, 0, 0xff, 0xff, 0xff, 0x7f})
+	assert.Error(t, err)
+	_, err = UnmarshalWKB([]byte{1, 3, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0})
+	assert.Error(t, err)
+	//polygon with an empty ring
+	g, err = UnmarshalWKB([]byte{1, 3, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0})
+	require.NoError(t, err)
+	assert.Equal(t, &Polygon{Rings: [][]float64{{}}}, g)
+	//multipoint containing a linestring
+	bad := []byte{1, 4, 0, 0, 0, 1, 0, 0, 0, 1, 2, 0, 0, 0, 0, 0, 0, 0}
+	_, err = UnmarshalWKB(bad)
+	assert.Error(t, err)
+	//xyz collection containing an xy point
+	mixed, _ := MarshalWKB(&Point{Coords: []float64{1, 2}})
+	bad = append([]byte{1, 0xef, 0x03, 0, 0, 1, 0, 0, 0}, mixed...)
+	_, err = UnmarshalWKB(bad)
+	assert.Error(t, err)
+}
@@ -0,0 +1,362 @@
+// Copyright 2021 Airbus Defence and Space
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package geom
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/airbusgeo/godal/ewkb"
+)
+
+const (
+	wkbPoint              = 1
+	wkbLineString         = 2
+	wkbPolygon            = 3
+	wkbMultiPoint         = 4
+	wkbMultiLineString    = 5
+	wkbMultiPolygon       = 6
+	wkbGeometryCollection = 7
+)
+
+// wkbWriter encodes geometries as little endian ISO WKB
+type wkbWriter struct {
+	buf []byte
+}
+
+func (w *wkbWriter) uint32(v uint32) {
+	w.buf = append(w.buf, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(w.buf[len(w.buf)-4:], v)
+}
+
+func (w *wkbWriter) float64(v float64) {
+	w.buf = append(w.buf, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint64(w.buf[len(w.buf)-8:], math.Float64bits(v))
+}
+
+func (w *wkbWriter) header(gtype uint32, layout Layout) {
+	w.buf = append(w.buf, 1) //little endian
+	switch layout {
+	case XYZ:
+		gtype += 1000
+	case XYM:
+		gtype += 2000
+	case XYZM:
+		gtype += 3000
+	}
+	w.uint32(gtype)
+}
+
+func (w *wkbWriter) coords(coords []float64, stride int, withCount bool) error {
+	if len(coords)%stride != 0 {
+		return fmt.Errorf("coordinates length %d is not a multiple of %d", len(coords), stride)
+	}
+	if withCount {
+		w.uint32(uint32(len(coords) / stride))
+	}
+	for _, v := range coords {
+		w.float64(v)
+	}
+	return nil
+}
+
+func (w *wkbWriter) point(coords []float64, layout Layout) error {
+	w.header(wkbPoint, layout)
+	stride := layout.Stride()
+	if len(coords) == 0 {
+		for i := 0; i < stride; i++ {
+			w.float64(math.NaN())
+		}
+		return nil
+	}
+	if len(coords) != stride {
+		return fmt.Errorf("point has %d coordinates instead of %d", len(coords), stride)
+	}
+	return w.coords(coords, stride, false)
+}
+
+func (w *wkbWriter) lineString(coords []float64, layout Layout) error {
+	w.header(wkbLineString, layout)
+	return w.coords(coords, layout.Stride(), true)
+}
+
+func (w *wkbWriter) polygon(rings [][]float64, layout Layout) error {
+	w.header(wkbPolygon, layout)
+	w.uint32(uint32(len(rings)))
+	for _, ring := range rings {
+		if err := w.coords(ring, layout.Stride(), true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *wkbWriter) geometry(g Geometry, layout Layout) error {
+	switch t := g.(type) {
+	case *Point:
+		return w.point(t.Coords, layout)
+	case *LineString:
+		return w.lineString(t.Coords, layout)
+	case *Polygon:
+		return w.polygon(t.Rings, layout)
+	case *MultiPoint:
+		w.header(wkbMultiPoint, layout)
+		w.uint32(uint32(len(t.Points)))
+		for _, p := range t.Points {
+			if err := w.point(p, layout); err != nil {
+				return err
+			}
+		}
+	case *MultiLineString:
+		w.header(wkbMultiLineString, layout)
+		w.uint32(uint32(len(t.Lines)))
+		for _, l := range t.Lines {
+			if err := w.lineString(l, layout); err != nil {
+				return err
+			}
+		}
+	case *MultiPolygon:
+		w.header(wkbMultiPolygon, layout)
+		w.uint32(uint32(len(t.Polygons)))
+		for _, p := range t.Polygons {
+			if err := w.polygon(p, layout); err != nil {
+				return err
+			}
+		}
+	case *GeometryCollection:
+		w.header(wkbGeometryCollection, layout)
+		w.uint32(uint32(len(t.Geometries)))
+		for _, sub := range t.Geometries {
+			if err := w.geometry(sub, layout); err != nil {
+				return err
+			}
+		}
+	case nil:
+		return fmt.Errorf("nil geometry")
+	default:
+		return fmt.Errorf("unsupported geometry type %T", g)
+	}
+	return nil
+}
+
+// MarshalWKB returns the ISO WKB representation of the geometry, in little endian byte
+// order. Z and M values are preserved, but the SRID is not.
+func MarshalWKB(g Geometry) ([]byte, error) {
+	if g == nil {
+		return nil, fmt.Errorf("nil geometry")
+	}
+	w := &wkbWriter{}
+	if err := w.geometry(g, g.GeometryHeader().Layout); err != nil {
+		return nil, err
+	}
+	return w.buf, nil
+}
+
+// MarshalEWKB returns the extended WKB representation of the geometry, as used by PostGIS,
+// in little endian byte order. The SRID is included if not 0.
+func MarshalEWKB(g Geometry) ([]byte, error) {
+	wkb, err := MarshalWKB(g)
+	if err != nil {
+		return nil, err
+	}
+	return ewkb.FromWKB(wkb, g.GeometryHeader().SRID)
+}
+
+// wkbReader decodes little endian ISO WKB, as returned by ewkb.ToWKB
+type wkbReader struct {
+	buf []byte
+}
+
+func (r *wkbReader) uint32() (uint32, error) {
+	if len(r.buf) < 4 {
+		return 0, fmt.Errorf("truncated wkb")
+	}
+	v := binary.LittleEndian.Uint32(r.buf)
+	r.buf = r.buf[4:]
+	return v, nil
+}
+
+// count reads a number of elements, each of which takes at least minSize bytes. This
+// prevents huge allocations on corrupted input.
+func (r *wkbReader) count(minSize int) (int, error) {
+	n, err := r.uint32()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(n)*uint64(minSize) > uint64(len(r.buf)) {
+		return 0, fmt.Errorf("truncated wkb")
+	}
+	return int(n), nil
+}
+
+func (r *wkbReader) coords(n, stride int) ([]float64, error) {
+	if len(r.buf) < n*stride*8 {
+		return nil, fmt.Errorf("truncated wkb")
+	}
+	ret := make([]float64, n*stride)
+	for i := range ret {
+		ret[i] = math.Float64frombits(binary.LittleEndian.Uint64(r.buf[i*8:]))
+	}
+	r.buf = r.buf[n*stride*8:]
+	return ret, nil
+}
+
+func (r *wkbReader) header() (uint32, Layout, error) {
+	if len(r.buf) < 1 {
+		return 0, XY, fmt.Errorf("truncated wkb")
+	}
+	r.buf = r.buf[1:]
+	gtype, err := r.uint32()
+	if err != nil {
+		return 0, XY, err
+	}
+	layout := XY
+	switch gtype / 1000 {
+	case 1:
+		layout = XYZ
+	case 2:
+		layout = XYM
+	case 3:
+		layout = XYZM
+	}
+	return gtype % 1000, layout, nil
+}
+
+func (r *wkbReader) points(stride int) ([]float64, error) {
+	n, err := r.count(stride * 8)
+	if err != nil {
+		return nil, err
+	}
+	return r.coords(n, stride)
+}
+
+func (r *wkbReader) rings(stride int) ([][]float64, error) {
+	// each ring takes at least the 4 bytes of its point count
+	nrings, err := r.count(4)
+	if err != nil {
+		return nil, err
+	}
+	rings := make([][]float64, nrings)
+	for i := range rings {
+		if rings[i], err = r.points(stride); err != nil {
+			return nil, err
+		}
+	}
+	return rings, nil
+}
+
+func (r *wkbReader) geometry(expected uint32, parent *Header) (Geometry, error) {
+	gtype, layout, err := r.header()
+	if err != nil {
+		return nil, err
+	}
+	if expected != 0 && gtype != expected {
+		return nil, fmt.Errorf("unexpected wkb geometry type %d in collection", gtype)
+	}
+	h := Header{Layout: layout}
+	if parent != nil {
+		if layout != parent.Layout {
+			return nil, fmt.Errorf("collection member layout %s differs from %s", layout, parent.Layout)
+		}
+		h.SRID = parent.SRID
+	}
+	stride := layout.Stride()
+	switch gtype {
+	case wkbPoint:
+		coords, err := r.coords(1, stride)
+		if err != nil {
+			return nil, err
+		}
+		if math.IsNaN(coords[0]) && math.IsNaN(coords[1]) {
+			coords = nil
+		}
+		return &Point{Header: h, Coords: coords}, nil
+	case wkbLineString:
+		coords, err := r.points(stride)
+		if err != nil {
+			return nil, err
+		}
+		return &LineString{Header: h, Coords: coords}, nil
+	case wkbPolygon:
+		rings, err := r.rings(stride)
+		if err != nil {
+			return nil, err
+		}
+		return &Polygon{Header: h, Rings: rings}, nil
+	case wkbMultiPoint, wkbMultiLineString, wkbMultiPolygon, wkbGeometryCollection:
+		// each member takes at least a byte order and a type
+		n, err := r.count(5)
+		if err != nil {
+			return nil, err
+		}
+		subs := make([]Geometry, n)
+		var subType uint32
+		switch gtype {
+		case wkbMultiPoint:
+			subType = wkbPoint
+		case wkbMultiLineString:
+			subType = wkbLineString
+		case wkbMultiPolygon:
+			subType = wkbPolygon
+		}
+		for i := range subs {
+			if subs[i], err = r.geometry(subType, &h); err != nil {
+				return nil, err
+			}
+		}
+		switch gtype {
+		case wkbMultiPoint:
+			mp := &MultiPoint{Header: h, Points: make([][]float64, n)}
+			for i, sub := range subs {
+				mp.Points[i] = sub.(*Point).Coords
+			}
+			return mp, nil
+		case wkbMultiLineString:
+			ml := &MultiLineString{Header: h, Lines: make([][]float64, n)}
+			for i, sub := range subs {
+				ml.Lines[i] = sub.(*LineString).Coords
+			}
+			return ml, nil
+		case wkbMultiPolygon:
+			mp := &MultiPolygon{Header: h, Polygons: make([][][]float64, n)}
+			for i, sub := range subs {
+				mp.Polygons[i] = sub.(*Polygon).Rings
+			}
+			return mp, nil
+		default:
+			return &GeometryCollection{Header: h, Geometries: subs}, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported wkb geometry type %d", gtype)
+	}
+}
+
+// UnmarshalWKB decodes a geometry from its WKB representation. ISO WKB, extended WKB
+// (as used by PostGIS, including the SRID) and OGC 2.5D WKB are supported, in either byte
+// order. Curve geometry types are not supported.
+func UnmarshalWKB(b []byte) (Geometry, error) {
+	wkb, srid, err := ewkb.ToWKB(b)
+	if err != nil {
+		return nil, err
+	}
+	r := &wkbReader{buf: wkb}
+	g, err := r.geometry(0, nil)
+	if err != nil {
+		return nil, err
+	}
+	g.SetSRID(srid)
+	return g, nil
+}
@@ -0,0 +1,342 @@
+// Copyright 2021 Airbus Defence and Space
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package godal
+
+import (
+	"fmt"
+	"math"
+)
+
+// snapEpsilon is the tolerance (in pixels) used when snapping fractional pixel
+// coordinates, to absorb floating point noise in the geotransform computations
+const snapEpsilon = 1e-6
+
+// ApplyGeoTransform returns the georeferenced coordinates of the given pixel/line
+// position. Pixel/line coordinates are expressed relative to the top left corner of
+// the top left pixel, i.e. the center of the top left pixel is at 0.5,0.5.
+func ApplyGeoTransform(gt [6]float64, pixel, line float64) (float64, float64) {
+	return gt[0] + pixel*gt[1] + line*gt[2], gt[3] + pixel*gt[4] + line*gt[5]
+}
+
+// InvGeoTransform inverts a geotransform, i.e. returns the geotransform mapping
+// georeferenced coordinates to pixel/line positions. It returns an error if the
+// geotransform is degenerate.
+func InvGeoTransform(gt [6]float64) ([6]float64, error) {
+	det := gt[1]*gt[5] - gt[2]*gt[4]
+	mag := math.Max(math.Max(math.Abs(gt[1]), math.Abs(gt[2])), math.Max(math.Abs(gt[4]), math.Abs(gt[5])))
+	if det == 0 || math.Abs(det) <= 1e-10*mag*mag {
+		return [6]float64{}, fmt.Errorf("geotransform %v is not invertible", gt)
+	}
+	return [6]float64{
+		(gt[2]*gt[3] - gt[0]*gt[5]) / det,
+		gt[5] / det,
+		-gt[2] / det,
+		(gt[0]*gt[4] - gt[1]*gt[3]) / det,
+		-gt[4] / det,
+		gt[1] / det,
+	}, nil
+}
+
+// envelope returns the [minx,miny,maxx,maxy] bounds of the given points
+func envelope(x, y []float64) [4]float64 {
+	ret := [4]float64{x[0], y[0], x[0], y[0]}
+	for i := 1; i < len(x); i++ {
+		ret[0] = math.Min(ret[0], x[i])
+		ret[1] = math.Min(ret[1], y[i])
+		ret[2] = math.Max(ret[2], x[i])
+		ret[3] = math.Max(ret[3], y[i])
+	}
+	return ret
+}
+
+type pixelGeoOpts struct {
+	sr           *SpatialRef
+	errorHandler ErrorHandler
+}
+
+// PixelGeoOption is an option that can be passed to Dataset.PixelToGeo, Dataset.GeoToPixel,
+// Dataset.PixelToGeoPoints or Dataset.GeoToPixelPoints
+//
+// Available PixelGeoOptions are:
+//   - *SpatialRef: georeferenced coordinates are expressed in this SpatialRef instead of
+//     the dataset's one
+//   - ErrLogger
+type PixelGeoOption interface {
+	setPixelGeoOpt(o *pixelGeoOpts)
+}
+
+func (sr *SpatialRef) setPixelGeoOpt(o *pixelGeoOpts) {
+	o.sr = sr
+}
+
+func geoTransformOpts(eh ErrorHandler) []GetGeoTransformOption {
+	if eh == nil {
+		return nil
+	}
+	return []GetGeoTransformOption{errorCallback{eh}}
+}
+
+// srsTransform returns the transform between the dataset's SpatialRef and sr, or from
+// sr to the dataset's SpatialRef if toDataset is set
+func (ds *Dataset) srsTransform(sr *SpatialRef, toDataset bool, eh ErrorHandler) (*Transform, error) {
+	dssr := ds.SpatialRef()
+	if dssr.handle == nil {
+		return nil, fmt.Errorf("dataset has no spatial reference")
+	}
+	topts := []TransformOption{}
+	if eh != nil {
+		topts = append(topts, errorCallback{eh})
+	}
+	var trn *Transform
+	var err error
+	if toDataset {
+		trn, err = NewTransform(sr, dssr, topts...)
+	} else {
+		trn, err = NewTransform(dssr, sr, topts...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create coordinate transform: %w", err)
+	}
+	return trn, nil
+}
+
+// PixelToGeo returns the georeferenced coordinates of the given pixel/line position,
+// using the dataset's geotransform. See ApplyGeoTransform.
+func (ds *Dataset) PixelToGeo(pixel, line float64, opts ...PixelGeoOption) (float64, float64, error) {
+	x, y := []float64{pixel}, []float64{line}
+	if err := ds.PixelToGeoPoints(x, y, opts...); err != nil {
+		return 0, 0, err
+	}
+	return x[0], y[0], nil
+}
+
+// GeoToPixel returns the (fractional) pixel/line position of the given georeferenced
+// coordinates, using the dataset's geotransform. Use math.Floor on the returned values
+// to obtain the indexes of the pixel containing the point.
+func (ds *Dataset) GeoToPixel(x, y float64, opts ...PixelGeoOption) (float64, float64, error) {
+	px, py := []float64{x}, []float64{y}
+	if err := ds.GeoToPixelPoints(px, py, opts...); err != nil {
+		return 0, 0, err
+	}
+	return px[0], py[0], nil
+}
+
+// PixelToGeoPoints converts in place pixel/line positions to georeferenced coordinates.
+// x and y must be of the same length.
+func (ds *Dataset) PixelToGeoPoints(x, y []float64, opts ...PixelGeoOption) error {
+	po := pixelGeoOpts{}
+	for _, o := range opts {
+		o.setPixelGeoOpt(&po)
+	}
+	if len(x) != len(y) {
+		return fmt.Errorf("x and y must be of the same length")
+	}
+	gt, err := ds.GeoTransform(geoTransformOpts(po.errorHandler)...)
+	if err != nil {
+		return fmt.Errorf("get geotransform: %w", err)
+	}
+	for i := range x {
+		x[i], y[i] = ApplyGeoTransform(gt, x[i], y[i])
+	}
+	if po.sr == nil || len(x) == 0 {
+		return nil
+	}
+	trn, err := ds.srsTransform(po.sr, false, po.errorHandler)
+	if err != nil {
+		return err
+	}
+	defer trn.Close()
+	return trn.TransformEx(x, y, nil, nil)
+}
+
+// GeoToPixelPoints converts in place georeferenced coordinates to pixel/line positions.
+// x and y must be of the same length.
+func (ds *Dataset) GeoToPixelPoints(x, y []float64, opts ...PixelGeoOption) error {
+	po := pixelGeoOpts{}
+	for _, o := range opts {
+		o.setPixelGeoOpt(&po)
+	}
+	if len(x) != len(y) {
+		return fmt.Errorf("x and y must be of the same length")
+	}
+	gt, err := ds.GeoTransform(geoTransformOpts(po.errorHandler)...)
+	if err != nil {
+		return fmt.Errorf("get geotransform: %w", err)
+	}
+	inv, err := InvGeoTransform(gt)
+	if err != nil {
+		return err
+	}
+	if po.sr != nil && len(x) > 0 {
+		trn, err := ds.srsTransform(po.sr, true, po.errorHandler)
+		if err != nil {
+			return err
+		}
+		defer trn.Close()
+		if err = trn.TransformEx(x, y, nil, nil); err != nil {
+			return err
+		}
+	}
+	for i := range x {
+		x[i], y[i] = ApplyGeoTransform(inv, x[i], y[i])
+	}
+	return nil
+}
+
+// SnapMode controls how fractional pixel positions are rounded when computing a window
+// from georeferenced bounds
+type SnapMode int
+
+const (
+	// SnapOut returns the smallest window containing the bounds, i.e. including the
+	// pixels that are only partially covered by the bounds
+	SnapOut SnapMode = iota
+	// SnapIn returns the largest window contained in the bounds, i.e. excluding the
+	// pixels that are only partially covered by the bounds
+	SnapIn
+	// SnapNearest rounds the edges of the window to the nearest pixel boundary
+	SnapNearest
+)
+
+type windowFromBoundsOpts struct {
+	sr           *SpatialRef
+	snap         SnapMode
+	densifyPts   int
+	errorHandler ErrorHandler
+}
+
+// WindowFromBoundsOption is an option that can be passed to Dataset.WindowFromBounds
+//
+// Available WindowFromBoundsOptions are:
+//   - *SpatialRef: the bounds are expressed in this SpatialRef instead of the dataset's one
+//   - Snap
+//   - DensifyPoints
+//   - ErrLogger
+type WindowFromBoundsOption interface {
+	setWindowFromBoundsOpt(o *windowFromBoundsOpts)
+}
+
+func (sr *SpatialRef) setWindowFromBoundsOpt(o *windowFromBoundsOpts) {
+	o.sr = sr
+}
+
+type snapOpt struct {
+	mode SnapMode
+}
+
+// Snap sets how the window edges are rounded to pixel boundaries. Defaults to SnapOut.
+func Snap(mode SnapMode) interface {
+	WindowFromBoundsOption
+} {
+	return snapOpt{mode}
+}
+
+func (so snapOpt) setWindowFromBoundsOpt(o *windowFromBoundsOpts) {
+	o.snap = so.mode
+}
+
+func snapRange(lo, hi float64, mode SnapMode) (int, int) {
+	switch mode {
+	case SnapIn:
+		return int(math.Ceil(lo - snapEpsilon)), int(math.Floor(hi + snapEpsilon))
+	case SnapNearest:
+		return int(math.Round(lo)), int(math.Round(hi))
+	default:
+		return int(math.Floor(lo + snapEpsilon)), int(math.Ceil(hi - snapEpsilon))
+	}
+}
+
+// WindowFromBounds returns the window of the dataset covering the given [minx,miny,maxx,maxy]
+// bounds, snapped to pixel boundaries according to the Snap option. The returned window
+// is clipped to the extent of the dataset, and an error is returned if it is empty.
+//
+// For rotated geotransforms, the window covers the envelope of the bounds expressed in
+// pixel/line space.
+func (ds *Dataset) WindowFromBounds(bounds [4]float64, opts ...WindowFromBoundsOption) (Block, error) {
+	wo := windowFromBoundsOpts{densifyPts: defaultDensifyPts}
+	for _, o := range opts {
+		o.setWindowFromBoundsOpt(&wo)
+	}
+	if wo.snap != SnapOut && wo.snap != SnapIn && wo.snap != SnapNearest {
+		return Block{}, fmt.Errorf("invalid snap mode %d", wo.snap)
+	}
+	if wo.sr != nil {
+		dssr := ds.SpatialRef()
+		if dssr.handle == nil {
+			return Block{}, fmt.Errorf("dataset has no spatial reference")
+		}
+		var err error
+		if bounds, err = reprojectBounds(bounds, wo.sr, dssr, wo.densifyPts, wo.errorHandler); err != nil {
+			return Block{}, err
+		}
+	}
+	x := []float64{bounds[0], bounds[0], bounds[2], bounds[2]}
+	y := []float64{bounds[1], bounds[3], bounds[3], bounds[1]}
+	pgopts := []PixelGeoOption{}
+	if wo.errorHandler != nil {
+		pgopts = append(pgopts, errorCallback{wo.errorHandler})
+	}
+	if err := ds.GeoToPixelPoints(x, y, pgopts...); err != nil {
+		return Block{}, err
+	}
+	env := envelope(x, y)
+	x0, x1 := snapRange(env[0], env[2], wo.snap)
+	y0, y1 := snapRange(env[1], env[3], wo.snap)
+	st := ds.Structure()
+	if x0 < 0 {
+		x0 = 0
+	}
+	if y0 < 0 {
+		y0 = 0
+	}
+	if x1 > st.SizeX {
+		x1 = st.SizeX
+	}
+	if y1 > st.SizeY {
+		y1 = st.SizeY
+	}
+	if x1 <= x0 || y1 <= y0 {
+		return Block{}, fmt.Errorf("bounds %v do not cover any pixel of the dataset", bounds)
+	}
+	return Block{X0: x0, Y0: y0, W: x1 - x0, H: y1 - y0}, nil
+}
+
+// WindowBounds returns the [minx,miny,maxx,maxy] bounds of the given window of the
+// dataset, optionally reprojected to a given SpatialRef. The window may extend
+// outside of the dataset.
+func (ds *Dataset) WindowBounds(window Block, opts ...BoundsOption) ([4]float64, error) {
+	bo := boundsOpts{densifyPts: defaultDensifyPts}
+	for _, o := range opts {
+		o.setBoundsOpt(&bo)
+	}
+	gt, err := ds.GeoTransform(geoTransformOpts(bo.errorHandler)...)
+	if err != nil {
+		return [4]float64{}, fmt.Errorf("get geotransform: %w", err)
+	}
+	x0, y0 := float64(window.X0), float64(window.Y0)
+	x1, y1 := float64(window.X0+window.W), float64(window.Y0+window.H)
+	x, y := make([]float64, 4), make([]float64, 4)
+	x[0], y[0] = ApplyGeoTransform(gt, x0, y0)
+	x[1], y[1] = ApplyGeoTransform(gt, x1, y0)
+	x[2], y[2] = ApplyGeoTransform(gt, x1, y1)
+	x[3], y[3] = ApplyGeoTransform(gt, x0, y1)
+	ret := envelope(x, y)
+	if bo.sr != nil {
+		srcsr := ds.SpatialRef()
+		defer srcsr.Close()
+		return reprojectBounds(ret, srcsr, bo.sr, bo.densifyPts, bo.errorHandler)
+	}
+	return ret, nil
+}
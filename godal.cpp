@@ -15,7 +15,9 @@
 #define _GNU_SOURCE 1
 #include "godal.h"
 #include <gdal.h>
+#include <gdal_priv.h>
 #include <ogr_srs_api.h>
+#include <ogr_spatialref.h>
 #include <cpl_conv.h>
 #include "cpl_port.h"
 #include "cpl_string.h"
@@ -24,6 +26,7 @@
 #include <gdal_frmts.h>
 #include <ogrsf_frmts.h>
 #include <dlfcn.h>
+#include <proj.h>
 #include <cassert>
 
 #include <gdal_utils.h>
@@ -35,6 +38,8 @@ extern "C" {
 	extern int _gogdalMultiReadCallback(char* key, int nRanges, void* pocbuffers, void* coffsets, void* clengths, char** errorString);
 	extern size_t _gogdalReadCallback(char* key, void* buffer, size_t off, size_t clen, char** errorString);
 	extern int goErrorHandler(int loggerID, CPLErr lvl, int code, const char *msg);
+	extern int _gogdalProgressCallback(double complete, char *msg, int progressIdx);
+	extern void _gogdalCalcCallback(char *expr, char *vars, char *srcnodata, char *nodata, int nSources, double *srcs, double *out, long long npix, char **errorString);
 }
 
 static void godalErrorHandler(CPLErr e, CPLErrorNum n, const char* msg) {
@@ -162,6 +167,17 @@ GDALDatasetH godalOpen(cctx *ctx, const char *name, unsigned int nOpenFlags, con
 	return ret;
 }
 
+GDALDriverH godalIdentifyDriver(cctx *ctx, const char *name, unsigned int nIdentifyFlags, char **papszAllowedDrivers, char **papszSiblingFiles) {
+	godalWrap(ctx);
+	GDALDriverH ret = GDALIdentifyDriverEx(name,nIdentifyFlags,papszAllowedDrivers,papszSiblingFiles);
+	godalUnwrap();
+	return ret;
+}
+
+char **godalGetOpenOptions(GDALDatasetH ds) {
+	return GDALDataset::FromHandle(ds)->GetOpenOptions();
+}
+
 void godalClose(cctx *ctx, GDALDatasetH ds) {
 	godalWrap(ctx);
 	GDALClose(ds);
@@ -296,10 +312,70 @@ char *godalExportToWKT(cctx *ctx, OGRSpatialReferenceH sr) {
 	return pszSRS;
 }
 
-OGRSpatialReferenceH godalCreateWKTSpatialRef(cctx *ctx, char *wkt){
+char *godalExportToWKTEx(cctx *ctx, OGRSpatialReferenceH sr, char **options) {
+	godalWrap(ctx);
+	char *pszSRS = nullptr;
+#if GDAL_VERSION_NUM < GDAL_COMPUTE_VERSION(3, 1, 0)
+	// gdal < 3.1 only knows the ISO 19162:2019 format by its draft name
+	char **opts = CSLDuplicate(options);
+	const char *format = CSLFetchNameValue(opts, "FORMAT");
+	if (format != nullptr && EQUAL(format, "WKT2_2019")) {
+		opts = CSLSetNameValue(opts, "FORMAT", "WKT2_2018");
+	}
+	OGRErr gret = OSRExportToWktEx(sr, &pszSRS, opts);
+	CSLDestroy(opts);
+#else
+	OGRErr gret = OSRExportToWktEx(sr, &pszSRS, options);
+#endif
+	if (gret != OGRERR_NONE) {
+		forceOGRError(ctx, gret);
+	}
+	if (failed(ctx)) {
+		CPLFree(pszSRS);
+		pszSRS = nullptr;
+	}
+	godalUnwrap();
+	return pszSRS;
+}
+
+char *godalExportToPROJJSON(cctx *ctx, OGRSpatialReferenceH sr, char **options) {
+	godalWrap(ctx);
+	char *pszSRS = nullptr;
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 1, 0)
+	OGRErr gret = OSRExportToPROJJSON(sr, &pszSRS, options);
+	if (gret != OGRERR_NONE) {
+		forceOGRError(ctx, gret);
+	}
+	if (failed(ctx)) {
+		CPLFree(pszSRS);
+		pszSRS = nullptr;
+	}
+#else
+	CPLError(CE_Failure, CPLE_NotSupported, "OSRExportToPROJJSON not supported with gdal < 3.1");
+#endif
+	godalUnwrap();
+	return pszSRS;
+}
+
+char *godalExportToProj4(cctx *ctx, OGRSpatialReferenceH sr) {
+	godalWrap(ctx);
+	char *pszSRS = nullptr;
+	OGRErr gret = OSRExportToProj4(sr, &pszSRS);
+	if (gret != OGRERR_NONE) {
+		forceOGRError(ctx, gret);
+	}
+	if (failed(ctx)) {
+		CPLFree(pszSRS);
+		pszSRS = nullptr;
+	}
+	godalUnwrap();
+	return pszSRS;
+}
+
+OGRSpatialReferenceH godalCreateWKTSpatialRef(cctx *ctx, char *wkt, int axisStrategy){
 	godalWrap(ctx);
 	OGRSpatialReferenceH sr = OSRNewSpatialReference(nullptr);
-	OSRSetAxisMappingStrategy(sr, OAMS_TRADITIONAL_GIS_ORDER);
+	OSRSetAxisMappingStrategy(sr, (OSRAxisMappingStrategy)axisStrategy);
 	OGRErr gret = OSRImportFromWkt(sr, &wkt);
 	if(gret!=0) {
 		forceOGRError(ctx,gret);
@@ -312,10 +388,10 @@ OGRSpatialReferenceH godalCreateWKTSpatialRef(cctx *ctx, char *wkt){
 	return sr;
 }
 
-OGRSpatialReferenceH godalCreateProj4SpatialRef(cctx *ctx, char *proj) {
+OGRSpatialReferenceH godalCreateProj4SpatialRef(cctx *ctx, char *proj, int axisStrategy) {
 	godalWrap(ctx);
 	OGRSpatialReferenceH sr = OSRNewSpatialReference(nullptr);
-	OSRSetAxisMappingStrategy(sr, OAMS_TRADITIONAL_GIS_ORDER);
+	OSRSetAxisMappingStrategy(sr, (OSRAxisMappingStrategy)axisStrategy);
 	OGRErr gret = OSRImportFromProj4(sr, proj);
 	if(gret!=0) {
 		forceOGRError(ctx,gret);
@@ -328,10 +404,10 @@ OGRSpatialReferenceH godalCreateProj4SpatialRef(cctx *ctx, char *proj) {
 	return sr;
 }
 
-OGRSpatialReferenceH godalCreateEPSGSpatialRef(cctx *ctx, int epsgCode) {
+OGRSpatialReferenceH godalCreateEPSGSpatialRef(cctx *ctx, int epsgCode, int axisStrategy) {
 	godalWrap(ctx);
 	OGRSpatialReferenceH sr = OSRNewSpatialReference(nullptr);
-	OSRSetAxisMappingStrategy(sr, OAMS_TRADITIONAL_GIS_ORDER);
+	OSRSetAxisMappingStrategy(sr, (OSRAxisMappingStrategy)axisStrategy);
 	OGRErr gret = OSRImportFromEPSG(sr, epsgCode);
 	if(gret!=0) {
 		forceOGRError(ctx,gret);
@@ -344,10 +420,10 @@ OGRSpatialReferenceH godalCreateEPSGSpatialRef(cctx *ctx, int epsgCode) {
 	return sr;
 }
 
-OGRSpatialReferenceH godalCreateUserSpatialRef(cctx *ctx, char *userInput) {
+OGRSpatialReferenceH godalCreateUserSpatialRef(cctx *ctx, char *userInput, int axisStrategy) {
 	godalWrap(ctx);
 	OGRSpatialReferenceH sr = OSRNewSpatialReference(nullptr);
-	OSRSetAxisMappingStrategy(sr, OAMS_TRADITIONAL_GIS_ORDER);
+	OSRSetAxisMappingStrategy(sr, (OSRAxisMappingStrategy)axisStrategy);
 	OGRErr gret = OSRSetFromUserInput(sr, userInput);
 	if(gret!=0) {
 		forceOGRError(ctx,gret);
@@ -360,6 +436,146 @@ OGRSpatialReferenceH godalCreateUserSpatialRef(cctx *ctx, char *userInput) {
 	return sr;
 }
 
+OGRSpatialReferenceH godalCreateCompoundSpatialRef(cctx *ctx, char *name, OGRSpatialReferenceH horiz, OGRSpatialReferenceH vert, int axisStrategy) {
+	godalWrap(ctx);
+	OGRSpatialReferenceH sr = OSRNewSpatialReference(nullptr);
+	OSRSetAxisMappingStrategy(sr, (OSRAxisMappingStrategy)axisStrategy);
+	OGRErr gret = OSRSetCompoundCS(sr, name, horiz, vert);
+	if(gret!=0) {
+		forceOGRError(ctx,gret);
+	}
+	godalUnwrap();
+	if( failed(ctx) ) {
+		OSRDestroySpatialReference(sr);
+		return nullptr;
+	}
+	return sr;
+}
+
+int godalSpatialRefIs3D(OGRSpatialReferenceH sr) {
+	if (OSRIsCompound(sr) || OSRIsGeocentric(sr)) {
+		return 1;
+	}
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 1, 0)
+	return OSRGetAxesCount(sr) >= 3;
+#else
+	return 0;
+#endif
+}
+
+void godalPromoteTo3D(cctx *ctx, OGRSpatialReferenceH sr, char *name) {
+	godalWrap(ctx);
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 1, 0)
+	OGRErr gret = OSRPromoteTo3D(sr, name);
+	if(gret!=0) {
+		forceOGRError(ctx,gret);
+	}
+#else
+	CPLError(CE_Failure, CPLE_NotSupported, "OSRPromoteTo3D not supported with gdal < 3.1");
+#endif
+	godalUnwrap();
+}
+
+void godalDemoteTo2D(cctx *ctx, OGRSpatialReferenceH sr, char *name) {
+	godalWrap(ctx);
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 2, 0)
+	OGRErr gret = OSRDemoteTo2D(sr, name);
+	if(gret!=0) {
+		forceOGRError(ctx,gret);
+	}
+#else
+	CPLError(CE_Failure, CPLE_NotSupported, "OSRDemoteTo2D not supported with gdal < 3.2");
+#endif
+	godalUnwrap();
+}
+
+int godalLongitudeFirst(OGRSpatialReferenceH sr) {
+	int n = 0;
+	const int *mapping = OSRGetDataAxisToSRSAxisMapping(sr, &n);
+	if (n < 1 || mapping[0] == 0) {
+		return 1;
+	}
+	OGRAxisOrientation orientation = OAO_Other;
+	OSRGetAxis(sr, nullptr, abs(mapping[0]) - 1, &orientation);
+	return orientation == OAO_East || orientation == OAO_West;
+}
+
+double godalGetCoordinateEpoch(OGRSpatialReferenceH sr) {
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 4, 0)
+	return OSRGetCoordinateEpoch(sr);
+#else
+	return 0;
+#endif
+}
+
+void godalSetCoordinateEpoch(cctx *ctx, OGRSpatialReferenceH sr, double epoch) {
+	godalWrap(ctx);
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 4, 0)
+	OSRSetCoordinateEpoch(sr, epoch);
+#else
+	CPLError(CE_Failure, CPLE_NotSupported, "OSRSetCoordinateEpoch not supported with gdal < 3.4");
+#endif
+	godalUnwrap();
+}
+
+OSRCRSInfo **godalGetCRSInfoList(cctx *ctx, char *authName, int *count) {
+	godalWrap(ctx);
+	*count = 0;
+	OSRCRSInfo **list = OSRGetCRSInfoListFromDatabase(authName, nullptr, count);
+	if (list == nullptr) {
+		forceError(ctx);
+	}
+	godalUnwrap();
+	return list;
+}
+
+OGRSpatialReferenceH *godalFindMatches(cctx *ctx, OGRSpatialReferenceH sr, int *count, int **confidences) {
+	godalWrap(ctx);
+	*count = 0;
+	*confidences = nullptr;
+	OGRSpatialReferenceH *matches = OSRFindMatches(sr, nullptr, count, confidences);
+	godalUnwrap();
+	return matches;
+}
+
+void godalFreeMatches(OGRSpatialReferenceH *matches, int *confidences) {
+	OSRFreeSRSArray(matches);
+	CPLFree(confidences);
+}
+
+int godalIsSameSpatialRef(OGRSpatialReferenceH sr, OGRSpatialReferenceH other, char **options) {
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 1, 0)
+	return OSRIsSameEx(sr, other, options);
+#else
+	return OSRIsSame(sr, other);
+#endif
+}
+
+OGRSpatialReferenceH godalCloneGeogCS(cctx *ctx, OGRSpatialReferenceH sr) {
+	godalWrap(ctx);
+	OGRSpatialReferenceH geog = OSRCloneGeogCS(sr);
+	if (geog == nullptr) {
+		forceError(ctx);
+	} else {
+		OSRSetAxisMappingStrategy(geog, OSRGetAxisMappingStrategy(sr));
+	}
+	godalUnwrap();
+	return geog;
+}
+
+void godalStripVertical(cctx *ctx, OGRSpatialReferenceH sr) {
+	godalWrap(ctx);
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 6, 0)
+	OGRErr gret = OSRStripVertical(sr);
+	if(gret!=0) {
+		forceOGRError(ctx,gret);
+	}
+#else
+	CPLError(CE_Failure, CPLE_NotSupported, "OSRStripVertical not supported with gdal < 3.6");
+#endif
+	godalUnwrap();
+}
+
 void godalValidateSpatialRef(cctx *ctx, OGRSpatialReferenceH sr) {
 	godalWrap(ctx);
 	OGRErr gret = OSRValidate(sr);
@@ -379,6 +595,274 @@ OGRCoordinateTransformationH godalNewCoordinateTransformation(cctx *ctx, OGRSpat
 	return tr;
 }
 
+OGRCoordinateTransformationH godalNewCoordinateTransformationEx(cctx *ctx, OGRSpatialReferenceH src, OGRSpatialReferenceH dst, godalCTOptions *opts) {
+	godalWrap(ctx);
+	OGRCoordinateTransformationOptionsH o = OCTNewCoordinateTransformationOptions();
+	if (opts->hasAreaOfInterest) {
+		OCTCoordinateTransformationOptionsSetAreaOfInterest(o, opts->west, opts->south, opts->east, opts->north);
+	}
+	if (opts->operation != nullptr) {
+		OCTCoordinateTransformationOptionsSetOperation(o, opts->operation, opts->reverse);
+	}
+	if (opts->desiredAccuracy > 0) {
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 3, 0)
+		OCTCoordinateTransformationOptionsSetDesiredAccuracy(o, opts->desiredAccuracy);
+#else
+		CPLError(CE_Failure, CPLE_NotSupported, "OCTCoordinateTransformationOptionsSetDesiredAccuracy not supported with gdal < 3.3");
+#endif
+	}
+	if (opts->ballpark >= 0) {
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 3, 0)
+		OCTCoordinateTransformationOptionsSetBallparkAllowed(o, opts->ballpark);
+#else
+		CPLError(CE_Failure, CPLE_NotSupported, "OCTCoordinateTransformationOptionsSetBallparkAllowed not supported with gdal < 3.3");
+#endif
+	}
+	OGRCoordinateTransformationH tr = nullptr;
+	if (!failed(ctx)) {
+		tr = OCTNewCoordinateTransformationEx(src, dst, o);
+		if (tr == nullptr) {
+			forceError(ctx);
+		}
+	}
+	OCTDestroyCoordinateTransformationOptions(o);
+	godalUnwrap();
+	return tr;
+}
+
+OGRSpatialReferenceH godalTransformSourceCS(OGRCoordinateTransformationH trn) {
+	return OGRSpatialReference::ToHandle(const_cast<OGRSpatialReference *>(OGRCoordinateTransformation::FromHandle(trn)->GetSourceCS()));
+}
+
+OGRSpatialReferenceH godalTransformTargetCS(OGRCoordinateTransformationH trn) {
+	return OGRSpatialReference::ToHandle(const_cast<OGRSpatialReference *>(OGRCoordinateTransformation::FromHandle(trn)->GetTargetCS()));
+}
+
+OGRCoordinateTransformationH godalCloneCoordinateTransformation(cctx *ctx, OGRCoordinateTransformationH trn) {
+	godalWrap(ctx);
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 1, 0)
+	OGRCoordinateTransformation *clone = OGRCoordinateTransformation::FromHandle(trn)->Clone();
+	if (clone == nullptr) {
+		forceError(ctx);
+	}
+	godalUnwrap();
+	return OGRCoordinateTransformation::ToHandle(clone);
+#else
+	CPLError(CE_Failure, CPLE_NotSupported, "OGRCoordinateTransformation::Clone not supported with gdal < 3.1");
+	godalUnwrap();
+	return nullptr;
+#endif
+}
+
+/* godalTransformInterleaved transforms n points stored as consecutive tuples of dim (>=2)
+ * coordinates, of which only the first 2 or 3 are used. The points are processed by chunks
+ * in stack buffers so that no allocation is needed on either side. */
+int godalTransformInterleaved(OGRCoordinateTransformationH trn, int n, int dim, double *coords, unsigned char *success) {
+	const int chunk = 256;
+	double x[chunk], y[chunk], z[chunk];
+	int ok[chunk];
+	int ret = TRUE;
+	for (int start = 0; start < n; start += chunk) {
+		int count = n - start < chunk ? n - start : chunk;
+		double *c = coords + (size_t)start * dim;
+		for (int i = 0; i < count; i++) {
+			x[i] = c[i * dim];
+			y[i] = c[i * dim + 1];
+			if (dim > 2) {
+				z[i] = c[i * dim + 2];
+			}
+		}
+		if (!OCTTransformEx(trn, count, x, y, dim > 2 ? z : nullptr, ok)) {
+			ret = FALSE;
+		}
+		for (int i = 0; i < count; i++) {
+			c[i * dim] = x[i];
+			c[i * dim + 1] = y[i];
+			if (dim > 2) {
+				c[i * dim + 2] = z[i];
+			}
+			if (!ok[i]) {
+				ret = FALSE;
+			}
+			if (success != nullptr) {
+				success[start + i] = ok[i] ? 1 : 0;
+			}
+		}
+	}
+	return ret;
+}
+
+/* godalProjContext creates a PROJ context configured with the PROJ settings (search paths,
+ * network access) of GDAL, so that the operations that are listed are the ones GDAL
+ * would consider when instantiating a transformation. */
+static PJ_CONTEXT *godalProjContext() {
+	PJ_CONTEXT *pctx = proj_context_create();
+	if (pctx == nullptr) {
+		return nullptr;
+	}
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 0, 3)
+	char **paths = OSRGetPROJSearchPaths();
+	if (paths != nullptr && paths[0] != nullptr) {
+		proj_context_set_search_paths(pctx, CSLCount(paths), paths);
+	}
+	CSLDestroy(paths);
+#endif
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 4, 0) && PROJ_VERSION_MAJOR >= 7
+	proj_context_set_enable_network(pctx, OSRGetPROJEnableNetwork());
+#endif
+	return pctx;
+}
+
+static PJ *godalProjCRS(PJ_CONTEXT *pctx, OGRSpatialReferenceH sr) {
+	char *wkt = nullptr;
+	const char *const options[] = {"FORMAT=WKT2_2018", nullptr};
+	if (OSRExportToWktEx(sr, &wkt, options) != OGRERR_NONE) {
+		CPLFree(wkt);
+		return nullptr;
+	}
+	PJ *crs = proj_create(pctx, wkt);
+	CPLFree(wkt);
+	return crs;
+}
+
+static void godalFillCoordOp(PJ_CONTEXT *pctx, PJ *op, godalCoordOp *ret) {
+	const char *name = proj_get_name(op);
+	ret->name = CPLStrdup(name ? name : "");
+	const char *proj = proj_as_proj_string(pctx, op, PJ_PROJ_5, nullptr);
+	ret->proj = CPLStrdup(proj ? proj : "");
+	ret->accuracy = proj_coordoperation_get_accuracy(pctx, op);
+	ret->instantiable = proj_coordoperation_is_instantiable(pctx, op);
+	const char *areaName = nullptr;
+	ret->hasAreaOfUse = proj_get_area_of_use(pctx, op, &ret->west, &ret->south, &ret->east, &ret->north, &areaName);
+	ret->areaName = CPLStrdup(areaName ? areaName : "");
+}
+
+godalCoordOp *godalCoordinateOperations(cctx *ctx, OGRSpatialReferenceH src, OGRSpatialReferenceH dst, godalCTOptions *opts, int discardMissingGrids, int *count) {
+	godalWrap(ctx);
+	*count = 0;
+	PJ_CONTEXT *pctx = godalProjContext();
+	if (pctx == nullptr) {
+		CPLError(CE_Failure, CPLE_AppDefined, "failed to create PROJ context");
+		godalUnwrap();
+		return nullptr;
+	}
+	godalCoordOp *ret = nullptr;
+	PJ *psrc = nullptr;
+	PJ *pdst = nullptr;
+	PJ_OPERATION_FACTORY_CONTEXT *fctx = nullptr;
+	PJ_OBJ_LIST *ops = nullptr;
+	if (opts->operation != nullptr) {
+		PJ *op = proj_create(pctx, opts->operation);
+		if (op == nullptr) {
+			CPLError(CE_Failure, CPLE_AppDefined, "invalid coordinate operation %s", opts->operation);
+		} else {
+			ret = (godalCoordOp *)CPLCalloc(1, sizeof(godalCoordOp));
+			godalFillCoordOp(pctx, op, ret);
+			*count = 1;
+			proj_destroy(op);
+		}
+		goto cleanup;
+	}
+	psrc = godalProjCRS(pctx, src);
+	pdst = godalProjCRS(pctx, dst);
+	if (psrc == nullptr || pdst == nullptr) {
+		CPLError(CE_Failure, CPLE_AppDefined, "failed to convert spatial references to PROJ objects");
+		goto cleanup;
+	}
+	fctx = proj_create_operation_factory_context(pctx, nullptr);
+	if (opts->hasAreaOfInterest) {
+		proj_operation_factory_context_set_area_of_interest(pctx, fctx, opts->west, opts->south, opts->east, opts->north);
+	}
+	if (opts->desiredAccuracy > 0) {
+		proj_operation_factory_context_set_desired_accuracy(pctx, fctx, opts->desiredAccuracy);
+	}
+	if (opts->ballpark >= 0) {
+#if PROJ_VERSION_MAJOR > 8 || (PROJ_VERSION_MAJOR == 8 && PROJ_VERSION_MINOR >= 1)
+		proj_operation_factory_context_set_allow_ballpark_transformations(pctx, fctx, opts->ballpark);
+#else
+		CPLError(CE_Failure, CPLE_NotSupported, "controlling ballpark transformations requires PROJ >= 8.1");
+		goto cleanup;
+#endif
+	}
+	proj_operation_factory_context_set_spatial_criterion(pctx, fctx, PROJ_SPATIAL_CRITERION_PARTIAL_INTERSECTION);
+	if (!discardMissingGrids) {
+		proj_operation_factory_context_set_grid_availability_use(pctx, fctx, PROJ_GRID_AVAILABILITY_USED_FOR_SORTING);
+	} else {
+#if PROJ_VERSION_MAJOR >= 7
+		/* same selection as proj_create_crs_to_crs, used by gdal to instantiate transforms */
+		proj_operation_factory_context_set_grid_availability_use(pctx, fctx,
+			proj_context_is_network_enabled(pctx) ? PROJ_GRID_AVAILABILITY_KNOWN_AVAILABLE : PROJ_GRID_AVAILABILITY_DISCARD_OPERATION_IF_MISSING_GRID);
+#else
+		proj_operation_factory_context_set_grid_availability_use(pctx, fctx, PROJ_GRID_AVAILABILITY_DISCARD_OPERATION_IF_MISSING_GRID);
+#endif
+	}
+	ops = proj_create_operations(pctx, psrc, pdst, fctx);
+	if (ops == nullptr) {
+		forceError(ctx);
+		goto cleanup;
+	}
+	*count = proj_list_get_count(ops);
+	if (*count > 0) {
+		ret = (godalCoordOp *)CPLCalloc(*count, sizeof(godalCoordOp));
+		for (int i = 0; i < *count; i++) {
+			PJ *op = proj_list_get(pctx, ops, i);
+			godalFillCoordOp(pctx, op, &ret[i]);
+			proj_destroy(op);
+		}
+	}
+cleanup:
+	proj_list_destroy(ops);
+	proj_operation_factory_context_destroy(fctx);
+	proj_destroy(psrc);
+	proj_destroy(pdst);
+	proj_context_destroy(pctx);
+	godalUnwrap();
+	return ret;
+}
+
+void godalFreeCoordinateOperations(godalCoordOp *ops, int count) {
+	for (int i = 0; i < count; i++) {
+		CPLFree(ops[i].name);
+		CPLFree(ops[i].proj);
+		CPLFree(ops[i].areaName);
+	}
+	CPLFree(ops);
+}
+
+int godalTransformBounds(cctx *ctx, OGRCoordinateTransformationH trn, double *bnds, int densifyPts) {
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 4, 0)
+	godalWrap(ctx);
+	double out[4];
+	if ( !OCTTransformBounds(trn, bnds[0], bnds[1], bnds[2], bnds[3], &out[0], &out[1], &out[2], &out[3], densifyPts) ) {
+		forceError(ctx);
+	} else {
+		memcpy(bnds, out, 4*sizeof(double));
+	}
+	godalUnwrap();
+	return 1;
+#else
+	return 0;
+#endif
+}
+
+void *godalCreateGenImgProjTransformer(cctx *ctx, GDALDatasetH src, GDALDatasetH dst, char **options) {
+	godalWrap(ctx);
+	void *tr = GDALCreateGenImgProjTransformer2(src, dst, options);
+	if ( tr == nullptr ) {
+		forceError(ctx);
+	}
+	godalUnwrap();
+	return tr;
+}
+
+void godalGenImgProjTransform(cctx *ctx, void *tr, int dstToSrc, int n, double *x, double *y, double *z, int *success) {
+	godalWrap(ctx);
+	if ( !GDALGenImgProjTransform(tr, dstToSrc, n, x, y, z, success) ) {
+		forceError(ctx);
+	}
+	godalUnwrap();
+}
+
 void godalSetGeoTransform(cctx *ctx, GDALDatasetH ds, double *gt){
 	godalWrap(ctx);
 	CPLErr ret = GDALSetGeoTransform(ds,gt);
@@ -440,6 +924,21 @@ OGRLayerH* godalVectorLayers(GDALDatasetH ds) {
 	return ret;
 }
 
+static const char *godalBandDriverName(GDALRasterBandH bnd) {
+	GDALDatasetH ds = GDALGetBandDataset(bnd);
+	GDALDriverH drv = ds == nullptr ? nullptr : GDALGetDatasetDriver(ds);
+	return drv == nullptr ? "unknown" : GDALGetDriverShortName(drv);
+}
+
+// godalCheckNoDataSet makes sure an explicit error is raised when a driver refuses a
+// per-band nodata value
+static void godalCheckNoDataSet(cctx *ctx, GDALRasterBandH bnd, CPLErr ret) {
+	if(ret!=0 && ctx->errMessage == nullptr && ctx->failed==0) {
+		CPLError(CE_Failure, CPLE_NotSupported, "%s driver rejected the nodata value of band %d",
+				 godalBandDriverName(bnd), GDALGetBandNumber(bnd));
+	}
+}
+
 void godalSetDatasetNoDataValue(cctx *ctx, GDALDatasetH ds, double nd) {
 	godalWrap(ctx);
 	int count = GDALGetRasterCount(ds);
@@ -448,25 +947,111 @@ void godalSetDatasetNoDataValue(cctx *ctx, GDALDatasetH ds, double nd) {
 		godalUnwrap();
 		return;
 	}
-	CPLErr ret = CE_None;
 	for(int i=1; i<=count;i++) {
-		CPLErr br = GDALSetRasterNoDataValue(GDALGetRasterBand(ds,i),nd);
-		if(br!=0 && ret==0) {
-			ret = br;
+		GDALRasterBandH bnd = GDALGetRasterBand(ds,i);
+		godalCheckNoDataSet(ctx, bnd, GDALSetRasterNoDataValue(bnd,nd));
+	}
+	godalUnwrap();
+}
+
+void godalSetRasterNoDataValue(cctx *ctx, GDALRasterBandH bnd, double nd) {
+	godalWrap(ctx);
+	godalCheckNoDataSet(ctx, bnd, GDALSetRasterNoDataValue(bnd,nd));
+	godalUnwrap();
+}
+
+long long godalGetRasterNoDataValueAsInt64(GDALRasterBandH bnd, int *ok) {
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 5, 0)
+	if(GDALGetRasterDataType(bnd)==GDT_Int64) {
+		return GDALGetRasterNoDataValueAsInt64(bnd, ok);
+	}
+	if(GDALGetRasterDataType(bnd)==GDT_UInt64) {
+		uint64_t nd = GDALGetRasterNoDataValueAsUInt64(bnd, ok);
+		if(*ok && nd > (uint64_t)INT64_MAX) {
+			*ok = 0;
 		}
+		return *ok ? (long long)nd : 0;
 	}
-	if ( ret != 0 ) {
-		forceCPLError(ctx,ret);
+#endif
+	double nd = GDALGetRasterNoDataValue(bnd, ok);
+	if(*ok && !(nd >= -9223372036854775808.0 && nd < 9223372036854775808.0 && nd == (double)(long long)nd)) {
+		*ok = 0;
+	}
+	return *ok ? (long long)nd : 0;
+}
+
+unsigned long long godalGetRasterNoDataValueAsUInt64(GDALRasterBandH bnd, int *ok) {
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 5, 0)
+	if(GDALGetRasterDataType(bnd)==GDT_UInt64) {
+		return GDALGetRasterNoDataValueAsUInt64(bnd, ok);
+	}
+	if(GDALGetRasterDataType(bnd)==GDT_Int64) {
+		int64_t nd = GDALGetRasterNoDataValueAsInt64(bnd, ok);
+		if(*ok && nd < 0) {
+			*ok = 0;
+		}
+		return *ok ? (unsigned long long)nd : 0;
+	}
+#endif
+	double nd = GDALGetRasterNoDataValue(bnd, ok);
+	if(*ok && !(nd >= 0 && nd < 18446744073709551616.0 && nd == (double)(unsigned long long)nd)) {
+		*ok = 0;
+	}
+	return *ok ? (unsigned long long)nd : 0;
+}
+
+// godalSetExactDoubleNoData sets an integer nodata value on a band whose nodata value is
+// stored as a double, provided the value can be represented exactly
+static void godalSetExactDoubleNoData(cctx *ctx, GDALRasterBandH bnd, double nd, bool exact) {
+	if(!exact) {
+		CPLError(CE_Failure, CPLE_AppDefined, "nodata value of band %d cannot be represented exactly as a %s",
+				 GDALGetBandNumber(bnd), GDALGetDataTypeName(GDALGetRasterDataType(bnd)));
+		return;
 	}
+	godalCheckNoDataSet(ctx, bnd, GDALSetRasterNoDataValue(bnd, nd));
+}
+
+void godalSetRasterNoDataValueAsInt64(cctx *ctx, GDALRasterBandH bnd, long long nd) {
+	godalWrap(ctx);
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 5, 0)
+	if(GDALGetRasterDataType(bnd)==GDT_Int64) {
+		godalCheckNoDataSet(ctx, bnd, GDALSetRasterNoDataValueAsInt64(bnd, nd));
+		godalUnwrap();
+		return;
+	}
+	if(GDALGetRasterDataType(bnd)==GDT_UInt64) {
+		if(nd < 0) {
+			CPLError(CE_Failure, CPLE_AppDefined, "negative nodata value on UInt64 band %d", GDALGetBandNumber(bnd));
+		} else {
+			godalCheckNoDataSet(ctx, bnd, GDALSetRasterNoDataValueAsUInt64(bnd, (uint64_t)nd));
+		}
+		godalUnwrap();
+		return;
+	}
+#endif
+	godalSetExactDoubleNoData(ctx, bnd, (double)nd, (double)nd < 9223372036854775808.0 && (long long)(double)nd == nd);
 	godalUnwrap();
 }
 
-void godalSetRasterNoDataValue(cctx *ctx, GDALRasterBandH bnd, double nd) {
+void godalSetRasterNoDataValueAsUInt64(cctx *ctx, GDALRasterBandH bnd, unsigned long long nd) {
 	godalWrap(ctx);
-	CPLErr ret = GDALSetRasterNoDataValue(bnd,nd);
-	if(ret!=0){
-		forceCPLError(ctx,ret);
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 5, 0)
+	if(GDALGetRasterDataType(bnd)==GDT_UInt64) {
+		godalCheckNoDataSet(ctx, bnd, GDALSetRasterNoDataValueAsUInt64(bnd, nd));
+		godalUnwrap();
+		return;
 	}
+	if(GDALGetRasterDataType(bnd)==GDT_Int64) {
+		if(nd > (unsigned long long)INT64_MAX) {
+			CPLError(CE_Failure, CPLE_AppDefined, "nodata value too large for Int64 band %d", GDALGetBandNumber(bnd));
+		} else {
+			godalCheckNoDataSet(ctx, bnd, GDALSetRasterNoDataValueAsInt64(bnd, (int64_t)nd));
+		}
+		godalUnwrap();
+		return;
+	}
+#endif
+	godalSetExactDoubleNoData(ctx, bnd, (double)nd, (double)nd < 18446744073709551616.0 && (unsigned long long)(double)nd == nd);
 	godalUnwrap();
 }
 
@@ -524,6 +1109,105 @@ void godalSetRasterScaleOffset(cctx *ctx, GDALRasterBandH bnd, double scale, dou
 	godalUnwrap();
 }
 
+void godalRATReadColumn(cctx *ctx, GDALRasterAttributeTableH rat, int iCol, int nRows, int *ints, double *doubles, char **strings) {
+	godalWrap(ctx);
+	CPLErr ret;
+	switch(GDALRATGetTypeOfCol(rat, iCol)) {
+	case GFT_Integer:
+		ret = GDALRATValuesIOAsInteger(rat, GF_Read, iCol, 0, nRows, ints);
+		break;
+	case GFT_Real:
+		ret = GDALRATValuesIOAsDouble(rat, GF_Read, iCol, 0, nRows, doubles);
+		break;
+	default:
+		ret = GDALRATValuesIOAsString(rat, GF_Read, iCol, 0, nRows, strings);
+		break;
+	}
+	if(ret!=CE_None) {
+		forceCPLError(ctx,ret);
+	}
+	godalUnwrap();
+}
+
+void godalSetRasterAttributeTable(cctx *ctx, GDALRasterBandH bnd, int tableType, int linearBinning, double row0Min, double binSize,
+								  int nCols, char **names, int *types, int *usages, int nRows, int *ints, double *doubles, char **strings) {
+	godalWrap(ctx);
+	if(nCols==0) {
+		CPLErr ret = GDALSetDefaultRAT(bnd, nullptr);
+		if(ret!=0){
+			forceCPLError(ctx,ret);
+		}
+		godalUnwrap();
+		return;
+	}
+	GDALRasterAttributeTableH rat = GDALCreateRasterAttributeTable();
+	GDALRATSetTableType(rat, (GDALRATTableType)tableType);
+	if(linearBinning) {
+		GDALRATSetLinearBinning(rat, row0Min, binSize);
+	}
+	GDALRATSetRowCount(rat, nRows);
+	CPLErr ret = CE_None;
+	for(int i=0; i<nCols && ret==CE_None; i++) {
+		ret = GDALRATCreateColumn(rat, names[i], (GDALRATFieldType)types[i], (GDALRATFieldUsage)usages[i]);
+		if(ret!=CE_None || nRows==0) {
+			continue;
+		}
+		switch(types[i]) {
+		case GFT_Integer:
+			ret = GDALRATValuesIOAsInteger(rat, GF_Write, i, 0, nRows, ints);
+			ints += nRows;
+			break;
+		case GFT_Real:
+			ret = GDALRATValuesIOAsDouble(rat, GF_Write, i, 0, nRows, doubles);
+			doubles += nRows;
+			break;
+		default:
+			ret = GDALRATValuesIOAsString(rat, GF_Write, i, 0, nRows, strings);
+			strings += nRows;
+			break;
+		}
+	}
+	if(ret==CE_None) {
+		ret = GDALSetDefaultRAT(bnd, rat);
+	}
+	if(ret!=CE_None) {
+		forceCPLError(ctx,ret);
+	}
+	GDALDestroyRasterAttributeTable(rat);
+	godalUnwrap();
+}
+
+void godalComputeRasterMinMax(cctx *ctx, GDALRasterBandH bnd, int bApproxOK, double *minmax) {
+	godalWrap(ctx);
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 7, 0)
+	CPLErr ret = GDALComputeRasterMinMax(bnd, bApproxOK, minmax);
+	if(ret!=0){
+		forceCPLError(ctx,ret);
+	}
+#else
+	GDALComputeRasterMinMax(bnd, bApproxOK, minmax);
+#endif
+	godalUnwrap();
+}
+
+void godalSetRasterCategoryNames(cctx *ctx, GDALRasterBandH bnd, char **names) {
+	godalWrap(ctx);
+	CPLErr ret = GDALSetRasterCategoryNames(bnd, names);
+	if(ret!=0){
+		forceCPLError(ctx,ret);
+	}
+	godalUnwrap();
+}
+
+void godalSetRasterUnitType(cctx *ctx, GDALRasterBandH bnd, const char *unit) {
+	godalWrap(ctx);
+	CPLErr ret = GDALSetRasterUnitType(bnd, unit);
+	if(ret!=0){
+		forceCPLError(ctx,ret);
+	}
+	godalUnwrap();
+}
+
 GDALRasterBandH godalCreateMaskBand(cctx *ctx, GDALRasterBandH bnd, int flags) {
 	godalWrap(ctx);
 	CPLErr ret = GDALCreateMaskBand(bnd, flags);
@@ -701,6 +1385,27 @@ void godalBandRasterIO(cctx *ctx, GDALRasterBandH bnd, GDALRWFlag rw, int nDSXOf
 	godalUnwrap();
 }
 
+void godalBandAdviseRead(cctx *ctx, GDALRasterBandH bnd, int nDSXOff, int nDSYOff, int nDSXSize, int nDSYSize,
+		int nBXSize, int nBYSize) {
+	godalWrap(ctx);
+	CPLErr ret = GDALRasterAdviseRead(bnd, nDSXOff, nDSYOff, nDSXSize, nDSYSize, nBXSize, nBYSize, GDT_Unknown, nullptr);
+	if(ret!=0){
+		forceCPLError(ctx,ret);
+	}
+	godalUnwrap();
+}
+
+void godalDatasetAdviseRead(cctx *ctx, GDALDatasetH ds, int nDSXOff, int nDSYOff, int nDSXSize, int nDSYSize,
+		int nBXSize, int nBYSize, int nBandCount, int *panBandCount) {
+	godalWrap(ctx);
+	CPLErr ret = GDALDatasetAdviseRead(ds, nDSXOff, nDSYOff, nDSXSize, nDSYSize, nBXSize, nBYSize, GDT_Unknown,
+									   nBandCount, panBandCount, nullptr);
+	if(ret!=0){
+		forceCPLError(ctx,ret);
+	}
+	godalUnwrap();
+}
+
 void godalDatasetRasterIO(cctx *ctx, GDALDatasetH ds, GDALRWFlag rw, int nDSXOff, int nDSYOff, int nDSXSize, int nDSYSize, void *pBuffer,
 		int nBXSize, int nBYSize, GDALDataType eBDataType, int nBandCount, int *panBandCount,
 		int nPixelSpace, int nLineSpace, int nBandSpace, GDALRIOResampleAlg alg) {
@@ -1913,4 +2618,118 @@ void godalGCPListToGeoTransform(cctx *ctx, goGCPList GCPList, int numGCPs, doubl
 	CPLFree(GDALGCPList);
 
 	godalUnwrap();
-}
\ No newline at end of file
+}
+void godalDeleteDataset(cctx *ctx, GDALDriverH drv, const char *name) {
+	godalWrap(ctx);
+	CPLErr ret = GDALDeleteDataset(drv, name);
+	if(ret!=0){
+		forceCPLError(ctx,ret);
+	}
+	godalUnwrap();
+}
+
+void godalRenameDataset(cctx *ctx, GDALDriverH drv, const char *newName, const char *oldName) {
+	godalWrap(ctx);
+	CPLErr ret = GDALRenameDataset(drv, newName, oldName);
+	if(ret!=0){
+		forceCPLError(ctx,ret);
+	}
+	godalUnwrap();
+}
+
+void godalCopyDatasetFiles(cctx *ctx, GDALDriverH drv, const char *newName, const char *oldName) {
+	godalWrap(ctx);
+	CPLErr ret = GDALCopyDatasetFiles(drv, newName, oldName);
+	if(ret!=0){
+		forceCPLError(ctx,ret);
+	}
+	godalUnwrap();
+}
+
+void godalValidateCreationOptions(cctx *ctx, GDALDriverH drv, char **options) {
+	godalWrap(ctx);
+	if(!GDALValidateCreationOptions(drv, options)) {
+		forceError(ctx);
+	}
+	godalUnwrap();
+}
+
+static int CPL_STDCALL godalProgress(double complete, const char *msg, void *data) {
+	return _gogdalProgressCallback(complete, (char *)msg, (int)(intptr_t)data);
+}
+
+GDALDatasetH godalCreateCopy(cctx *ctx, GDALDriverH drv, const char *name, GDALDatasetH src, int strict, char **options, int progressIdx) {
+	godalWrap(ctx);
+	GDALProgressFunc pfn = nullptr;
+	if(progressIdx!=0) {
+		pfn = godalProgress;
+	}
+	GDALDatasetH ret = GDALCreateCopy(drv, name, src, strict, options, pfn, (void *)(intptr_t)progressIdx);
+	if(ret==nullptr) {
+		forceError(ctx);
+	}
+	godalUnwrap();
+	return ret;
+}
+
+GDALDatasetH godalCalcCreateCopy(cctx *ctx, GDALDriverH drv, const char *name, GDALDatasetH src, char **options) {
+	godalWrap(ctx);
+	GDALDatasetH ret = GDALCreateCopy(drv, name, src, FALSE, options, nullptr, nullptr);
+	if(ret==nullptr) {
+		forceError(ctx);
+	}
+	godalUnwrap();
+	return ret;
+}
+
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 4, 0)
+static CPLErr godalCalcPixelFunc(void **papoSources, int nSources, void *pData, int nBufXSize, int nBufYSize,
+								 GDALDataType eSrcType, GDALDataType eBufType, int nPixelSpace, int nLineSpace,
+								 CSLConstList papszArgs) {
+	const char *expr = CSLFetchNameValue(papszArgs, "expression");
+	if(expr==nullptr) {
+		CPLError(CE_Failure, CPLE_AppDefined, "godal_calc: missing expression argument");
+		return CE_Failure;
+	}
+	const char *vars = CSLFetchNameValueDef(papszArgs, "variables", "");
+	const char *srcnd = CSLFetchNameValueDef(papszArgs, "srcnodata", "");
+	const char *dstnd = CSLFetchNameValueDef(papszArgs, "nodata", "");
+	size_t npix = (size_t)nBufXSize * nBufYSize;
+	double *srcs = (double *)VSI_MALLOC3_VERBOSE(npix, nSources + 1, sizeof(double));
+	if(srcs==nullptr) {
+		return CE_Failure;
+	}
+	double *out = srcs + npix * nSources;
+	int srcSize = GDALGetDataTypeSizeBytes(eSrcType);
+	for(int i=0; i<nSources; i++) {
+		GDALCopyWords64(papoSources[i], eSrcType, srcSize, srcs + npix * i, GDT_Float64, sizeof(double), npix);
+	}
+	char *err = nullptr;
+	_gogdalCalcCallback((char *)expr, (char *)vars, (char *)srcnd, (char *)dstnd, nSources, srcs, out, (long long)npix, &err);
+	if(err!=nullptr) {
+		CPLError(CE_Failure, CPLE_AppDefined, "godal_calc: %s", err);
+		free(err);
+		VSIFree(srcs);
+		return CE_Failure;
+	}
+	for(int y=0; y<nBufYSize; y++) {
+		GDALCopyWords64(out + (size_t)nBufXSize * y, GDT_Float64, sizeof(double),
+						(GByte *)pData + (GPtrDiff_t)nLineSpace * y, eBufType, nPixelSpace, nBufXSize);
+	}
+	VSIFree(srcs);
+	return CE_None;
+}
+#endif
+
+void godalRegisterCalcPixelFunction(cctx *ctx) {
+	godalWrap(ctx);
+#if GDAL_VERSION_NUM >= GDAL_COMPUTE_VERSION(3, 4, 0)
+	CPLErr ret = GDALAddDerivedBandPixelFuncWithArgs("godal_calc", godalCalcPixelFunc, nullptr);
+	if(ret!=0) {
+		forceCPLError(ctx, ret);
+	}
+#else
+	CPLError(CE_Failure, CPLE_NotSupported, "VRT calc output is only supported in GDAL version >= 3.4");
+#endif
+	godalUnwrap();
+}
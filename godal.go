@@ -18,19 +18,24 @@ package godal
 #include "godal.h"
 #include <stdlib.h>
 
-#cgo pkg-config: gdal
+#cgo pkg-config: gdal proj
 #cgo CXXFLAGS: -std=c++11
 #cgo LDFLAGS: -ldl
 */
 import "C"
 import (
+	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
 	"path/filepath"
+	"reflect"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 )
@@ -63,6 +68,10 @@ const (
 	CFloat32 = DataType(C.GDT_CFloat32)
 	//CFloat64 is a complex Float64
 	CFloat64 = DataType(C.GDT_CFloat64)
+	//Int64 DataType (GDAL >= 3.5)
+	Int64 = DataType(C.GDT_Int64)
+	//UInt64 DataType (GDAL >= 3.5)
+	UInt64 = DataType(C.GDT_UInt64)
 )
 
 // ErrorCategory wraps GDAL's error types
@@ -95,7 +104,7 @@ func (dtype DataType) Size() int {
 		return 2
 	case Int32, UInt32, Float32, CInt16:
 		return 4
-	case CInt32, Float64, CFloat32:
+	case CInt32, Float64, CFloat32, Int64, UInt64:
 		return 8
 	case CFloat64:
 		return 16
@@ -199,6 +208,56 @@ func (band Band) SetNoData(nd float64, opts ...SetNoDataOption) error {
 	return cgc.close()
 }
 
+// NoDataInt64 returns the band's nodata value as an int64, which is exact for Int64
+// bands (GDAL >= 3.5). if ok is false, the band does not have a nodata value set, or
+// its nodata value cannot be represented as an int64
+func (band Band) NoDataInt64() (nodata int64, ok bool) {
+	cok := C.int(0)
+	cn := C.godalGetRasterNoDataValueAsInt64(band.handle(), &cok)
+	if cok != 0 {
+		return int64(cn), true
+	}
+	return 0, false
+}
+
+// NoDataUInt64 returns the band's nodata value as an uint64, which is exact for UInt64
+// bands (GDAL >= 3.5). if ok is false, the band does not have a nodata value set, or
+// its nodata value cannot be represented as an uint64
+func (band Band) NoDataUInt64() (nodata uint64, ok bool) {
+	cok := C.int(0)
+	cn := C.godalGetRasterNoDataValueAsUInt64(band.handle(), &cok)
+	if cok != 0 {
+		return uint64(cn), true
+	}
+	return 0, false
+}
+
+// SetNoDataInt64 sets the band's nodata value without going through a float64, which
+// is required to set nodata values of Int64 bands exactly. For other datatypes, an
+// error is returned if nd cannot be represented exactly as a float64
+func (band Band) SetNoDataInt64(nd int64, opts ...SetNoDataOption) error {
+	sndo := &setNodataOpts{}
+	for _, opt := range opts {
+		opt.setSetNoDataOpt(sndo)
+	}
+	cgc := createCGOContext(nil, sndo.errorHandler)
+	C.godalSetRasterNoDataValueAsInt64(cgc.cPointer(), band.handle(), C.longlong(nd))
+	return cgc.close()
+}
+
+// SetNoDataUInt64 sets the band's nodata value without going through a float64, which
+// is required to set nodata values of UInt64 bands exactly. For other datatypes, an
+// error is returned if nd cannot be represented exactly as a float64
+func (band Band) SetNoDataUInt64(nd uint64, opts ...SetNoDataOption) error {
+	sndo := &setNodataOpts{}
+	for _, opt := range opts {
+		opt.setSetNoDataOpt(sndo)
+	}
+	cgc := createCGOContext(nil, sndo.errorHandler)
+	C.godalSetRasterNoDataValueAsUInt64(cgc.cPointer(), band.handle(), C.ulonglong(nd))
+	return cgc.close()
+}
+
 // ClearNoData clears the band's nodata value
 func (band Band) ClearNoData(opts ...SetNoDataOption) error {
 	sndo := &setNodataOpts{}
@@ -226,6 +285,95 @@ func (band Band) ClearScaleOffset(opts ...SetScaleOffsetOption) error {
 	return band.SetScaleOffset(1.0, 0.0, opts...)
 }
 
+// Scale returns the band's scale. if ok is false, the band does not have a scale set
+// and the returned scale is 1
+func (band Band) Scale() (scale float64, ok bool) {
+	cok := C.int(0)
+	cs := C.GDALGetRasterScale(band.handle(), &cok)
+	return float64(cs), cok != 0
+}
+
+// Offset returns the band's offset. if ok is false, the band does not have an offset set
+// and the returned offset is 0
+func (band Band) Offset() (offset float64, ok bool) {
+	cok := C.int(0)
+	co := C.GDALGetRasterOffset(band.handle(), &cok)
+	return float64(co), cok != 0
+}
+
+// Minimum returns the band's minimum value, as known by the driver or from previously
+// computed statistics. if ok is false, the minimum is not known and the returned value
+// is the minimum of the band's datatype
+func (band Band) Minimum() (min float64, ok bool) {
+	cok := C.int(0)
+	cm := C.GDALGetRasterMinimum(band.handle(), &cok)
+	return float64(cm), cok != 0
+}
+
+// Maximum returns the band's maximum value, as known by the driver or from previously
+// computed statistics. if ok is false, the maximum is not known and the returned value
+// is the maximum of the band's datatype
+func (band Band) Maximum() (max float64, ok bool) {
+	cok := C.int(0)
+	cm := C.GDALGetRasterMaximum(band.handle(), &cok)
+	return float64(cm), cok != 0
+}
+
+// ComputeMinMax computes the band's minimum and maximum values by scanning its pixels,
+// ignoring nodata values. With the Approximate option, the values may be computed from
+// overviews or a subset of the blocks.
+func (band Band) ComputeMinMax(opts ...ComputeMinMaxOption) (min, max float64, err error) {
+	cmo := computeMinMaxOpts{}
+	for _, opt := range opts {
+		opt.setComputeMinMaxOpt(&cmo)
+	}
+	var minmax [2]C.double
+	cgc := createCGOContext(nil, cmo.errorHandler)
+	C.godalComputeRasterMinMax(cgc.cPointer(), band.handle(), C.int(cmo.approx), &minmax[0])
+	if err := cgc.close(); err != nil {
+		return 0, 0, err
+	}
+	return float64(minmax[0]), float64(minmax[1]), nil
+}
+
+// CategoryNames returns the names of the band's categories, indexed by pixel value. It
+// returns nil if the band has no category names
+func (band Band) CategoryNames() []string {
+	return cStringArrayToSlice(C.GDALGetRasterCategoryNames(band.handle()))
+}
+
+// SetCategoryNames sets the names of the band's categories, indexed by pixel value. if
+// passing in a 0-length names, the band's category names will be cleared
+func (band Band) SetCategoryNames(names []string, opts ...SetCategoryNamesOption) error {
+	scno := &setCategoryNamesOpts{}
+	for _, opt := range opts {
+		opt.setSetCategoryNamesOpt(scno)
+	}
+	cnames := sliceToCStringArray(names)
+	defer cnames.free()
+	cgc := createCGOContext(nil, scno.errorHandler)
+	C.godalSetRasterCategoryNames(cgc.cPointer(), band.handle(), cnames.cPointer())
+	return cgc.close()
+}
+
+// Unit returns the band's unit type (e.g. "m" or "ft"), or an empty string if unknown
+func (band Band) Unit() string {
+	return C.GoString(C.GDALGetRasterUnitType(band.handle()))
+}
+
+// SetUnit sets the band's unit type
+func (band Band) SetUnit(unit string, opts ...SetUnitOption) error {
+	suo := &setUnitOpts{}
+	for _, opt := range opts {
+		opt.setSetUnitOpt(suo)
+	}
+	cunit := C.CString(unit)
+	defer C.free(unsafe.Pointer(cunit))
+	cgc := createCGOContext(nil, suo.errorHandler)
+	C.godalSetRasterUnitType(cgc.cPointer(), band.handle(), cunit)
+	return cgc.close()
+}
+
 // ColorInterp returns the band's color interpretation (defaults to Gray)
 func (band Band) ColorInterp() ColorInterp {
 	colorInterp := C.GDALGetRasterColorInterpretation(band.handle())
@@ -244,7 +392,67 @@ func (band Band) SetColorInterp(colorInterp ColorInterp, opts ...SetColorInterpO
 	return cgc.close()
 }
 
-// MaskFlags returns the mask flags associated with this band.
+const (
+	// MaskAllValid is set when all the pixels of the band are valid
+	MaskAllValid = int(C.GMF_ALL_VALID)
+	// MaskPerDataset is set when the mask band is shared between all the bands of the dataset
+	MaskPerDataset = int(C.GMF_PER_DATASET)
+	// MaskAlpha is set when the mask band is an alpha band, i.e. may contain values
+	// other than 0 and 255
+	MaskAlpha = int(C.GMF_ALPHA)
+	// MaskNoData is set when the mask band is computed from the nodata value(s)
+	MaskNoData = int(C.GMF_NODATA)
+)
+
+// BandMaskInfo describes how the validity of the pixels of a band is defined
+type BandMaskInfo struct {
+	// Flags are the band's mask flags, as returned by Band.MaskFlags()
+	Flags int
+	// NoData is the band's nodata value, if HasNoData is set
+	NoData    float64
+	HasNoData bool
+}
+
+// AllValid returns whether all the pixels of the band are valid
+func (bmi BandMaskInfo) AllValid() bool {
+	return bmi.Flags&MaskAllValid != 0
+}
+
+// UsesNoData returns whether invalid pixels are identified by the band's nodata value
+func (bmi BandMaskInfo) UsesNoData() bool {
+	return bmi.Flags&MaskNoData != 0
+}
+
+// UsesAlpha returns whether the validity of the pixels is given by the dataset's alpha band
+func (bmi BandMaskInfo) UsesAlpha() bool {
+	return bmi.Flags&MaskAlpha != 0
+}
+
+// UsesPerDatasetMask returns whether the validity of the pixels is given by a mask band
+// shared by all the bands of the dataset (including an alpha band)
+func (bmi BandMaskInfo) UsesPerDatasetMask() bool {
+	return bmi.Flags&MaskPerDataset != 0
+}
+
+// UsesPerBandMask returns whether the validity of the pixels is given by a mask band
+// specific to this band
+func (bmi BandMaskInfo) UsesPerBandMask() bool {
+	return bmi.Flags == 0
+}
+
+// DatasetMaskInfo describes how the validity of the pixels of a dataset is defined
+type DatasetMaskInfo struct {
+	// Bands contains the mask information of each of the dataset's bands
+	Bands []BandMaskInfo
+	// AlphaBand is the index (starting at 0) of the dataset's alpha band, or -1 if
+	// the dataset has no alpha band
+	AlphaBand int
+	// PerDatasetMask is set when at least one band uses a mask shared by all the bands
+	PerDatasetMask bool
+}
+
+// MaskFlags returns the mask flags associated with this band, as a combination of
+// MaskAllValid, MaskPerDataset, MaskAlpha and MaskNoData.
 //
 // See https://gdal.org/development/rfc/rfc15_nodatabitmask.html for how this flag
 // should be interpreted
@@ -333,12 +541,169 @@ func (band Band) IO(rw IOOperation, srcX, srcY int, buffer interface{}, bufWidth
 	if err != nil {
 		return err
 	}
-	cgc := createCGOContext(ro.config, ro.errorHandler)
-	C.godalBandRasterIO(cgc.cPointer(), band.handle(), C.GDALRWFlag(rw),
-		C.int(srcX), C.int(srcY), C.int(ro.dsWidth), C.int(ro.dsHeight),
-		cBuf,
-		C.int(bufWidth), C.int(bufHeight), C.GDALDataType(dtype),
-		C.int(pixelSpacing), C.int(lineSpacing), ralg)
+	if ro.mask != nil {
+		if rw != IORead {
+			return fmt.Errorf("ValidityMask can only be used when reading")
+		}
+		if pixelSpacing%dsize != 0 || lineSpacing%dsize != 0 {
+			return fmt.Errorf("pixel and line spacings must be multiples of the buffer item size")
+		}
+		if len(ro.mask) < bufWidth*bufHeight {
+			return fmt.Errorf("mask len=%d less than min=%d", len(ro.mask), bufWidth*bufHeight)
+		}
+	}
+	var sc scaler
+	if ro.scaleOffset || ro.complexValues {
+		if rw != IORead {
+			return fmt.Errorf("ApplyScaleOffset and ComplexValues can only be used when reading")
+		}
+		if pixelSpacing%dsize != 0 || lineSpacing%dsize != 0 {
+			return fmt.Errorf("pixel and line spacings must be multiples of the buffer item size")
+		}
+		if dtype != Float32 && dtype != Float64 {
+			return fmt.Errorf("ApplyScaleOffset and ComplexValues require a []float32 or []float64 buffer")
+		}
+		sc = band.scaler(ro.scaleOffset)
+	}
+	if ro.complexValues {
+		cplx := make([]complex128, bufWidth*bufHeight)
+		cgc := createCGOContext(ro.config, ro.errorHandler)
+		C.godalBandRasterIO(cgc.cPointer(), band.handle(), C.GF_Read,
+			C.int(srcX), C.int(srcY), C.int(ro.dsWidth), C.int(ro.dsHeight),
+			unsafe.Pointer(&cplx[0]),
+			C.int(bufWidth), C.int(bufHeight), C.GDT_CFloat64,
+			16, C.int(bufWidth*16), ralg)
+		if err := cgc.close(); err != nil {
+			return err
+		}
+		if err := extractComplex(cplx, ro.complexPart, sc, buffer, 0, pixelSpacing/dsize, lineSpacing/dsize,
+			bufWidth, bufHeight); err != nil {
+			return err
+		}
+	} else {
+		cgc := createCGOContext(ro.config, ro.errorHandler)
+		C.godalBandRasterIO(cgc.cPointer(), band.handle(), C.GDALRWFlag(rw),
+			C.int(srcX), C.int(srcY), C.int(ro.dsWidth), C.int(ro.dsHeight),
+			cBuf,
+			C.int(bufWidth), C.int(bufHeight), C.GDALDataType(dtype),
+			C.int(pixelSpacing), C.int(lineSpacing), ralg)
+		if err := cgc.close(); err != nil {
+			return err
+		}
+		if ro.scaleOffset {
+			if err := sc.apply(buffer, 0, pixelSpacing/dsize, lineSpacing/dsize, bufWidth, bufHeight); err != nil {
+				return err
+			}
+		}
+	}
+	if ro.mask == nil {
+		return nil
+	}
+	if err := readValidity([]Band{band}, srcX, srcY, ro.dsWidth, ro.dsHeight, ro.mask, bufWidth, bufHeight,
+		ralg, ro.config, ro.errorHandler); err != nil {
+		return err
+	}
+	flagNaNs(buffer, ro.mask, 0, pixelSpacing/dsize, lineSpacing/dsize, bufWidth, bufHeight)
+	return nil
+}
+
+// scaler returns the scaler converting the band's raw values to physical values. If
+// applyScale is false, the returned scaler only resolves nodata values
+func (band Band) scaler(applyScale bool) scaler {
+	sc := scaler{scale: 1}
+	if applyScale {
+		sc.scale = float64(C.GDALGetRasterScale(band.handle(), nil))
+		sc.offset = float64(C.GDALGetRasterOffset(band.handle(), nil))
+	}
+	sc.nodata, sc.hasNoData = band.NoData()
+	return sc
+}
+
+// readValidity populates mask with the combined validity of the given bands over the
+// supplied window
+func readValidity(bands []Band, srcX, srcY, dsWidth, dsHeight int, mask []byte, bufWidth, bufHeight int,
+	ralg C.GDALRIOResampleAlg, config []string, eh ErrorHandler) error {
+	n := bufWidth * bufHeight
+	if len(mask) < n {
+		return fmt.Errorf("mask len=%d less than min=%d", len(mask), n)
+	}
+	mask = mask[:n]
+	for i := range mask {
+		mask[i] = 255
+	}
+	var bmask []byte
+	perDataset := false
+	for _, band := range bands {
+		flags := band.MaskFlags()
+		if flags&MaskAllValid != 0 {
+			continue
+		}
+		if flags&MaskPerDataset != 0 {
+			if perDataset {
+				continue
+			}
+			perDataset = true
+		}
+		if bmask == nil {
+			bmask = make([]byte, n)
+		}
+		cgc := createCGOContext(config, eh)
+		C.godalBandRasterIO(cgc.cPointer(), band.MaskBand().handle(), C.GF_Read,
+			C.int(srcX), C.int(srcY), C.int(dsWidth), C.int(dsHeight),
+			unsafe.Pointer(&bmask[0]), C.int(bufWidth), C.int(bufHeight), C.GDT_Byte,
+			1, C.int(bufWidth), ralg)
+		if err := cgc.close(); err != nil {
+			return err
+		}
+		for i, v := range bmask {
+			if v < mask[i] {
+				mask[i] = v
+			}
+		}
+	}
+	return nil
+}
+
+// flagNaNs sets mask to 0 for NaN pixels contained in a float32 or float64 buffer. offset,
+// pixelStride and lineStride are expressed in number of items
+func flagNaNs(buffer interface{}, mask []byte, offset, pixelStride, lineStride, bufWidth, bufHeight int) {
+	switch buf := buffer.(type) {
+	case []float32:
+		for y := 0; y < bufHeight; y++ {
+			for x := 0; x < bufWidth; x++ {
+				if v := buf[offset+y*lineStride+x*pixelStride]; v != v {
+					mask[y*bufWidth+x] = 0
+				}
+			}
+		}
+	case []float64:
+		for y := 0; y < bufHeight; y++ {
+			for x := 0; x < bufWidth; x++ {
+				if v := buf[offset+y*lineStride+x*pixelStride]; v != v {
+					mask[y*bufWidth+x] = 0
+				}
+			}
+		}
+	}
+}
+
+// AdviseRead hints the driver that the pixels contained in the supplied window
+// are going to be read with a buffer of bufWidth x bufHeight pixels. See Dataset.AdviseRead.
+func (band Band) AdviseRead(srcX, srcY int, bufWidth, bufHeight int, opts ...BandAdviseReadOption) error {
+	ao := adviseReadOpts{}
+	for _, opt := range opts {
+		opt.setBandAdviseReadOpt(&ao)
+	}
+	if ao.dsHeight == 0 {
+		ao.dsHeight = bufHeight
+	}
+	if ao.dsWidth == 0 {
+		ao.dsWidth = bufWidth
+	}
+	cgc := createCGOContext(ao.config, ao.errorHandler)
+	C.godalBandAdviseRead(cgc.cPointer(), band.handle(),
+		C.int(srcX), C.int(srcY), C.int(ao.dsWidth), C.int(ao.dsHeight),
+		C.int(bufWidth), C.int(bufHeight))
 	return cgc.close()
 }
 
@@ -735,6 +1100,200 @@ func (band Band) SetColorTable(ct ColorTable, opts ...SetColorTableOption) error
 	return cgc.close()
 }
 
+// RATFieldType is the type of a raster attribute table column
+type RATFieldType C.GDALRATFieldType
+
+const (
+	//RATInteger is an integer column
+	RATInteger RATFieldType = C.GFT_Integer
+	//RATReal is a floating point column
+	RATReal RATFieldType = C.GFT_Real
+	//RATString is a string column
+	RATString RATFieldType = C.GFT_String
+)
+
+// RATFieldUsage is the usage of a raster attribute table column
+type RATFieldUsage C.GDALRATFieldUsage
+
+const (
+	//RATGeneric is a general purpose column
+	RATGeneric RATFieldUsage = C.GFU_Generic
+	//RATPixelCount contains the number of pixels of each class
+	RATPixelCount RATFieldUsage = C.GFU_PixelCount
+	//RATName contains the name of each class
+	RATName RATFieldUsage = C.GFU_Name
+	//RATMin contains the minimum pixel value of each class
+	RATMin RATFieldUsage = C.GFU_Min
+	//RATMax contains the maximum pixel value of each class
+	RATMax RATFieldUsage = C.GFU_Max
+	//RATMinMax contains the pixel value of each class
+	RATMinMax RATFieldUsage = C.GFU_MinMax
+	//RATRed contains the red color component (0-255) of each class
+	RATRed RATFieldUsage = C.GFU_Red
+	//RATGreen contains the green color component (0-255) of each class
+	RATGreen RATFieldUsage = C.GFU_Green
+	//RATBlue contains the blue color component (0-255) of each class
+	RATBlue RATFieldUsage = C.GFU_Blue
+	//RATAlpha contains the alpha component (0-255) of each class
+	RATAlpha RATFieldUsage = C.GFU_Alpha
+	//RATRedMin contains the red color component of the start of each range
+	RATRedMin RATFieldUsage = C.GFU_RedMin
+	//RATGreenMin contains the green color component of the start of each range
+	RATGreenMin RATFieldUsage = C.GFU_GreenMin
+	//RATBlueMin contains the blue color component of the start of each range
+	RATBlueMin RATFieldUsage = C.GFU_BlueMin
+	//RATAlphaMin contains the alpha component of the start of each range
+	RATAlphaMin RATFieldUsage = C.GFU_AlphaMin
+	//RATRedMax contains the red color component of the end of each range
+	RATRedMax RATFieldUsage = C.GFU_RedMax
+	//RATGreenMax contains the green color component of the end of each range
+	RATGreenMax RATFieldUsage = C.GFU_GreenMax
+	//RATBlueMax contains the blue color component of the end of each range
+	RATBlueMax RATFieldUsage = C.GFU_BlueMax
+	//RATAlphaMax contains the alpha component of the end of each range
+	RATAlphaMax RATFieldUsage = C.GFU_AlphaMax
+)
+
+// RATTableType tells whether a raster attribute table describes classes or ranges of values
+type RATTableType C.GDALRATTableType
+
+const (
+	//ThematicTable is a table whose rows describe categories
+	ThematicTable RATTableType = C.GRTT_THEMATIC
+	//AthematicTable is a table whose rows describe ranges of continuous values
+	AthematicTable RATTableType = C.GRTT_ATHEMATIC
+)
+
+// AttributeTable returns the band's raster attribute table. The returned AttributeTable
+// will have no Columns if the band has no attribute table assigned
+func (band Band) AttributeTable(opts ...AttributeTableOption) (AttributeTable, error) {
+	ato := &attributeTableOpts{}
+	for _, o := range opts {
+		o.setAttributeTableOpt(ato)
+	}
+	hrat := C.GDALGetDefaultRAT(band.handle())
+	if hrat == nil {
+		return AttributeTable{}, nil
+	}
+	at := AttributeTable{
+		TableType: RATTableType(C.GDALRATGetTableType(hrat)),
+	}
+	var row0Min, binSize C.double
+	if C.GDALRATGetLinearBinning(hrat, &row0Min, &binSize) != 0 {
+		at.LinearBinning = true
+		at.Row0Min = float64(row0Min)
+		at.BinSize = float64(binSize)
+	}
+	nRows := int(C.GDALRATGetRowCount(hrat))
+	nCols := int(C.GDALRATGetColumnCount(hrat))
+	at.Columns = make([]RATColumn, nCols)
+	for i := range at.Columns {
+		col := &at.Columns[i]
+		col.Name = C.GoString(C.GDALRATGetNameOfCol(hrat, C.int(i)))
+		col.Type = RATFieldType(C.GDALRATGetTypeOfCol(hrat, C.int(i)))
+		col.Usage = RATFieldUsage(C.GDALRATGetUsageOfCol(hrat, C.int(i)))
+		switch col.Type {
+		case RATInteger:
+			col.Integers = make([]int, nRows)
+		case RATReal:
+			col.Reals = make([]float64, nRows)
+		default:
+			col.Strings = make([]string, nRows)
+		}
+		if nRows == 0 {
+			continue
+		}
+		var ints []C.int
+		var cints *C.int
+		var cdoubles *C.double
+		var strs []*C.char
+		var cstrs **C.char
+		switch col.Type {
+		case RATInteger:
+			ints = make([]C.int, nRows)
+			cints = &ints[0]
+		case RATReal:
+			cdoubles = (*C.double)(unsafe.Pointer(&col.Reals[0]))
+		default:
+			strs = make([]*C.char, nRows)
+			cstrs = &strs[0]
+		}
+		cgc := createCGOContext(nil, ato.errorHandler)
+		C.godalRATReadColumn(cgc.cPointer(), hrat, C.int(i), C.int(nRows), cints, cdoubles, cstrs)
+		err := cgc.close()
+		for r, v := range ints {
+			col.Integers[r] = int(v)
+		}
+		for r, v := range strs {
+			if v != nil {
+				col.Strings[r] = C.GoString(v)
+				C.VSIFree(unsafe.Pointer(v))
+			}
+		}
+		if err != nil {
+			return AttributeTable{}, err
+		}
+	}
+	return at, nil
+}
+
+// SetAttributeTable sets the band's raster attribute table. if passing in an AttributeTable
+// with no Columns, the band's attribute table will be cleared
+func (band Band) SetAttributeTable(at AttributeTable, opts ...SetAttributeTableOption) error {
+	sato := &setAttributeTableOpts{}
+	for _, o := range opts {
+		o.setSetAttributeTableOpt(sato)
+	}
+	nRows, err := at.checkColumns()
+	if err != nil {
+		return err
+	}
+	nCols := len(at.Columns)
+	names := make([]string, nCols)
+	types := make([]C.int, nCols+1)
+	usages := make([]C.int, nCols+1)
+	var ints []C.int
+	var doubles []float64
+	var strs []string
+	for i, col := range at.Columns {
+		names[i] = col.Name
+		types[i] = C.int(col.Type)
+		usages[i] = C.int(col.Usage)
+		switch col.Type {
+		case RATInteger:
+			for _, v := range col.Integers {
+				ints = append(ints, C.int(v))
+			}
+		case RATReal:
+			doubles = append(doubles, col.Reals...)
+		default:
+			strs = append(strs, col.Strings...)
+		}
+	}
+	cnames := sliceToCStringArray(names)
+	defer cnames.free()
+	cstrs := sliceToCStringArray(strs)
+	defer cstrs.free()
+	var cints *C.int
+	if len(ints) > 0 {
+		cints = &ints[0]
+	}
+	var cdoubles *C.double
+	if len(doubles) > 0 {
+		cdoubles = (*C.double)(unsafe.Pointer(&doubles[0]))
+	}
+	linearBinning := 0
+	if at.LinearBinning {
+		linearBinning = 1
+	}
+	cgc := createCGOContext(nil, sato.errorHandler)
+	C.godalSetRasterAttributeTable(cgc.cPointer(), band.handle(), C.int(at.TableType),
+		C.int(linearBinning), C.double(at.Row0Min), C.double(at.BinSize),
+		C.int(nCols), cnames.cPointer(), &types[0], &usages[0],
+		C.int(nRows), cints, cdoubles, cstrs.cPointer())
+	return cgc.close()
+}
+
 // Bands returns all dataset bands.
 func (ds *Dataset) Bands() []Band {
 	cbands := C.godalRasterBands(ds.handle())
@@ -759,36 +1318,8 @@ func (ds *Dataset) Bands() []Band {
 //
 //	[MinX, MinY, MaxX, MaxY]
 func (ds *Dataset) Bounds(opts ...BoundsOption) ([4]float64, error) {
-
-	bo := boundsOpts{}
-	for _, o := range opts {
-		o.setBoundsOpt(&bo)
-	}
-	ret := [4]float64{}
 	st := ds.Structure()
-	gt, err := ds.GeoTransform()
-	if err != nil {
-		return ret, fmt.Errorf("get geotransform: %w", err)
-	}
-	ret[0] = gt[0]
-	ret[1] = gt[3]
-	ret[2] = gt[0] + float64(st.SizeX)*gt[1] + float64(st.SizeY)*gt[2]
-	ret[3] = gt[3] + float64(st.SizeX)*gt[4] + float64(st.SizeY)*gt[5]
-	if bo.sr != nil {
-		srcsr := ds.SpatialRef()
-		defer srcsr.Close()
-		ret, err = reprojectBounds(ret, srcsr, bo.sr)
-		if err != nil {
-			return ret, err
-		}
-	}
-	if ret[0] > ret[2] {
-		ret[2], ret[0] = ret[0], ret[2]
-	}
-	if ret[1] > ret[3] {
-		ret[3], ret[1] = ret[1], ret[3]
-	}
-	return ret, nil
+	return ds.WindowBounds(Block{W: st.SizeX, H: st.SizeY}, opts...)
 }
 
 // CreateMaskBand creates a mask (nodata) band shared for all bands of this dataset.
@@ -835,7 +1366,8 @@ func (ds *Dataset) SetProjection(wkt string, opts ...SetProjectionOption) error
 	return cgc.close()
 }
 
-// SpatialRef returns dataset projection.
+// SpatialRef returns dataset projection. For dynamic CRSs, the returned SpatialRef carries
+// the coordinate epoch if the driver stores it (see SpatialRef.CoordinateEpoch).
 func (ds *Dataset) SpatialRef() *SpatialRef {
 	hndl := C.GDALGetSpatialRef(ds.handle())
 	return &SpatialRef{handle: hndl, isOwned: false}
@@ -892,7 +1424,28 @@ func (ds *Dataset) SetGeoTransform(transform [6]float64, opts ...SetGeoTransform
 	return cgc.close()
 }
 
-// SetNoData sets the band's nodata value
+// MaskInfo returns how the validity of the pixels of each of the dataset's bands is
+// defined, i.e. whether they use a nodata value, a per-band mask, a mask shared by all
+// the bands, or an alpha band.
+func (ds *Dataset) MaskInfo() DatasetMaskInfo {
+	mi := DatasetMaskInfo{AlphaBand: -1}
+	for i, band := range ds.Bands() {
+		bmi := BandMaskInfo{Flags: band.MaskFlags()}
+		bmi.NoData, bmi.HasNoData = band.NoData()
+		if band.ColorInterp() == CIAlpha && mi.AlphaBand == -1 {
+			mi.AlphaBand = i
+		}
+		if bmi.Flags&MaskPerDataset != 0 {
+			mi.PerDatasetMask = true
+		}
+		mi.Bands = append(mi.Bands, bmi)
+	}
+	return mi
+}
+
+// SetNoData sets the nodata value of all the dataset's bands. An error is returned
+// if the driver rejects or ignores the value for any of the bands (e.g. because it
+// only supports a single nodata value shared by all bands)
 func (ds *Dataset) SetNoData(nd float64, opts ...SetNoDataOption) error {
 	sndo := &setNodataOpts{}
 	for _, opt := range opts {
@@ -914,6 +1467,110 @@ func (ds *Dataset) SetScaleOffset(scale, offset float64, opts ...SetScaleOffsetO
 	return cgc.close()
 }
 
+// FileList returns the list of files making up the dataset, i.e. its main file along
+// with its sidecar files (e.g. .ovr, .msk, .aux.xml). It returns an empty list for
+// datasets that are not backed by files.
+func (ds *Dataset) FileList() []string {
+	cfiles := C.GDALGetFileList(ds.handle())
+	defer C.CSLDestroy(cfiles)
+	files := cStringArrayToSlice(cfiles)
+	if files == nil {
+		files = []string{}
+	}
+	return files
+}
+
+// Subdataset is a dataset contained inside a container dataset (e.g. a netCDF variable, a
+// GeoPackage raster table or a Sentinel-2 SAFE resolution)
+type Subdataset struct {
+	// Name is the connection string that can be passed to Open()
+	Name string
+	// Description is a human readable description of the subdataset
+	Description string
+}
+
+// Subdatasets returns the subdatasets advertised by the dataset in its SUBDATASETS
+// metadata domain, in order.
+func (ds *Dataset) Subdatasets() []Subdataset {
+	md := ds.Metadatas(Domain("SUBDATASETS"))
+	sds := []Subdataset{}
+	for i := 1; ; i++ {
+		name, ok := md[fmt.Sprintf("SUBDATASET_%d_NAME", i)]
+		if !ok {
+			return sds
+		}
+		sds = append(sds, Subdataset{
+			Name:        name,
+			Description: md[fmt.Sprintf("SUBDATASET_%d_DESC", i)],
+		})
+	}
+}
+
+// OpenSubdataset opens the i-th (starting at 0) subdataset returned by Subdatasets(). The
+// driver open options the parent dataset was opened with are forwarded, after the ones
+// passed as DriverOpenOption.
+func (ds *Dataset) OpenSubdataset(i int, opts ...OpenOption) (*Dataset, error) {
+	sds := ds.Subdatasets()
+	if i < 0 || i >= len(sds) {
+		return nil, fmt.Errorf("subdataset %d out of range [0,%d)", i, len(sds))
+	}
+	oopts := append([]OpenOption{}, opts...)
+	if parentOpts := cStringArrayToSlice(C.godalGetOpenOptions(ds.handle())); len(parentOpts) > 0 {
+		oopts = append(oopts, DriverOpenOption(parentOpts...))
+	}
+	return Open(sds[i].Name, oopts...)
+}
+
+// CreateCopy wraps GDALCreateCopy and uses driver to create a copy of the dataset with the
+// given name (usually filename). Contrary to Create(), it can be used with drivers that can
+// only write a dataset in one go (e.g. COG, PNG or JPEG).
+//
+// The returned dataset must be closed by the caller.
+func (ds *Dataset) CreateCopy(driver DriverName, name string, opts ...CreateCopyOption) (*Dataset, error) {
+	drvname := string(driver)
+	if drv, ok := driverMappings[driver]; ok {
+		drvname = drv.rasterName
+		if drvname == "" {
+			drvname = drv.vectorName
+		}
+	}
+	drv, ok := getDriver(drvname)
+	if !ok {
+		return nil, fmt.Errorf("failed to get driver %s", drvname)
+	}
+	ccopts := createCopyOpts{}
+	for _, opt := range opts {
+		opt.setCreateCopyOpt(&ccopts)
+	}
+	if !drv.CanCreate() && !drv.CanCreateCopy() {
+		return nil, fmt.Errorf("driver %s supports neither Create nor CreateCopy", drvname)
+	}
+	createOpts := sliceToCStringArray(ccopts.creation)
+	cname := C.CString(name)
+	defer createOpts.free()
+	defer C.free(unsafe.Pointer(cname))
+	strict := 0
+	if ccopts.strict {
+		strict = 1
+	}
+	progressIdx := 0
+	if ccopts.progress != nil {
+		progressIdx = registerProgress(ccopts.progress)
+		defer unregisterProgress(progressIdx)
+	}
+
+	cgc := createCGOContext(ccopts.config, ccopts.errorHandler)
+	hndl := C.godalCreateCopy(cgc.cPointer(), drv.handle(), cname, ds.handle(), C.int(strict),
+		createOpts.cPointer(), C.int(progressIdx))
+	if err := cgc.close(); err != nil {
+		if hndl != nil {
+			C.GDALClose(hndl)
+		}
+		return nil, err
+	}
+	return &Dataset{majorObject{C.GDALMajorObjectH(hndl)}}, nil
+}
+
 // Translate runs the library version of gdal_translate.
 // See the gdal_translate doc page to determine the valid flags/opts that can be set in switches.
 //
@@ -1244,6 +1901,28 @@ func (ds *Dataset) IO(rw IOOperation, srcX, srcY int, buffer interface{}, bufWid
 	if err != nil {
 		return err
 	}
+	if ro.mask != nil {
+		if rw != IORead {
+			return fmt.Errorf("ValidityMask can only be used when reading")
+		}
+		if pixelSpacing%dsize != 0 || lineSpacing%dsize != 0 || bandSpacing%dsize != 0 {
+			return fmt.Errorf("pixel, line and band spacings must be multiples of the buffer item size")
+		}
+		if len(ro.mask) < bufWidth*bufHeight {
+			return fmt.Errorf("mask len=%d less than min=%d", len(ro.mask), bufWidth*bufHeight)
+		}
+	}
+	if ro.scaleOffset {
+		if rw != IORead {
+			return fmt.Errorf("ApplyScaleOffset can only be used when reading")
+		}
+		if pixelSpacing%dsize != 0 || lineSpacing%dsize != 0 || bandSpacing%dsize != 0 {
+			return fmt.Errorf("pixel, line and band spacings must be multiples of the buffer item size")
+		}
+		if dtype != Float32 && dtype != Float64 {
+			return fmt.Errorf("ApplyScaleOffset requires a []float32 or []float64 buffer")
+		}
+	}
 	cgc := createCGOContext(ro.config, ro.errorHandler)
 	C.godalDatasetRasterIO(cgc.cPointer(), ds.handle(), C.GDALRWFlag(rw),
 		C.int(srcX), C.int(srcY), C.int(ro.dsWidth), C.int(ro.dsHeight),
@@ -1251,6 +1930,61 @@ func (ds *Dataset) IO(rw IOOperation, srcX, srcY int, buffer interface{}, bufWid
 		C.int(bufWidth), C.int(bufHeight), C.GDALDataType(dtype),
 		C.int(len(ro.bands)), cIntArray(ro.bands),
 		C.int(pixelSpacing), C.int(lineSpacing), C.int(bandSpacing), ralg)
+	if err := cgc.close(); err != nil || (ro.mask == nil && !ro.scaleOffset) {
+		return err
+	}
+	bands = make([]Band, len(ro.bands))
+	for i, b := range ro.bands {
+		bands[i] = Band{majorObject{C.GDALMajorObjectH(C.GDALGetRasterBand(ds.handle(), C.int(b)))}}
+	}
+	if ro.scaleOffset {
+		for i, band := range bands {
+			if err := band.scaler(true).apply(buffer, i*bandSpacing/dsize, pixelSpacing/dsize, lineSpacing/dsize,
+				bufWidth, bufHeight); err != nil {
+				return err
+			}
+		}
+	}
+	if ro.mask == nil {
+		return nil
+	}
+	if err := readValidity(bands, srcX, srcY, ro.dsWidth, ro.dsHeight, ro.mask, bufWidth, bufHeight,
+		ralg, ro.config, ro.errorHandler); err != nil {
+		return err
+	}
+	for i := range bands {
+		flagNaNs(buffer, ro.mask, i*bandSpacing/dsize, pixelSpacing/dsize, lineSpacing/dsize, bufWidth, bufHeight)
+	}
+	return nil
+}
+
+// AdviseRead hints the driver that the pixels contained in the supplied window
+// are going to be read with a buffer of bufWidth x bufHeight pixels, so that it may
+// fetch them ahead of time in an efficient manner (e.g. by issuing the ranged
+// reads of all the tiles covering the window at once). Options are the same as
+// for Dataset.Read.
+//
+// Drivers that do not make use of this hint simply ignore it.
+func (ds *Dataset) AdviseRead(srcX, srcY int, bufWidth, bufHeight int, opts ...DatasetAdviseReadOption) error {
+	ao := adviseReadOpts{}
+	for _, opt := range opts {
+		opt.setDatasetAdviseReadOpt(&ao)
+	}
+	if ao.dsHeight == 0 {
+		ao.dsHeight = bufHeight
+	}
+	if ao.dsWidth == 0 {
+		ao.dsWidth = bufWidth
+	}
+	if ao.bands == nil {
+		for i := range ds.Bands() {
+			ao.bands = append(ao.bands, i+1)
+		}
+	}
+	cgc := createCGOContext(ao.config, ao.errorHandler)
+	C.godalDatasetAdviseRead(cgc.cPointer(), ds.handle(),
+		C.int(srcX), C.int(srcY), C.int(ao.dsWidth), C.int(ao.dsHeight),
+		C.int(bufWidth), C.int(bufHeight), C.int(len(ao.bands)), cIntArray(ao.bands))
 	return cgc.close()
 }
 
@@ -1291,7 +2025,12 @@ func RegisterPlugin(name string, opts ...RegisterPluginOption) error {
 // known to gdal but not explicitly defined inside this golang wrapper. Note that "XXX" must be provided
 // exactly (i.e. respecting uppercase/lowercase) the same as the names of the C functions GDALRegister_XXX()
 // that can be found in gdal.h
+//
+// Drivers that cannot be registered (e.g. because the linked gdal library was built without
+// them) do not prevent the registration of the other ones: all the requested drivers are
+// tried, and the returned error lists the ones that failed.
 func RegisterRaster(drivers ...DriverName) error {
+	var failed []string
 	for _, driver := range drivers {
 		switch driver {
 		case Memory:
@@ -1309,14 +2048,15 @@ func RegisterRaster(drivers ...DriverName) error {
 				fnname = drv.rasterRegister
 			}
 			if fnname == "" {
-				return fmt.Errorf("%s driver does not handle rasters", fnname)
+				failed = append(failed, fmt.Sprintf("%s driver does not handle rasters", driver))
+				continue
 			}
 			if err := registerDriver(fnname); err != nil {
-				return err
+				failed = append(failed, err.Error())
 			}
 		}
 	}
-	return nil
+	return registrationError(failed)
 }
 
 // RegisterVector registers a vector driver by name.
@@ -1329,7 +2069,12 @@ func RegisterRaster(drivers ...DriverName) error {
 // known to gdal but not explicitly defined inside this golang wrapper. Note that "XXX" must be provided
 // exactly (i.e. respecting uppercase/lowercase) the same as the names of the C functions RegisterOGRXXX()
 // that can be found in ogrsf_frmts.h
+//
+// Drivers that cannot be registered (e.g. because the linked gdal library was built without
+// them) do not prevent the registration of the other ones: all the requested drivers are
+// tried, and the returned error lists the ones that failed.
 func RegisterVector(drivers ...DriverName) error {
+	var failed []string
 	for _, driver := range drivers {
 		switch driver {
 		/* TODO: speedup for OGR drivers
@@ -1349,14 +2094,22 @@ func RegisterVector(drivers ...DriverName) error {
 				fnname = drv.vectorRegister
 			}
 			if fnname == "" {
-				return fmt.Errorf("%s driver does not handle vectors", fnname)
+				failed = append(failed, fmt.Sprintf("%s driver does not handle vectors", driver))
+				continue
 			}
 			if err := registerDriver(fnname); err != nil {
-				return err
+				failed = append(failed, err.Error())
 			}
 		}
 	}
-	return nil
+	return registrationError(failed)
+}
+
+func registrationError(failed []string) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(failed, "; "))
 }
 
 func registerDriver(fnname string) error {
@@ -1423,6 +2176,48 @@ func RasterDriver(name DriverName) (Driver, bool) {
 	return getDriver(string(name))
 }
 
+// RegisteredDrivers returns all the drivers that are currently registered
+func RegisteredDrivers() []Driver {
+	n := int(C.GDALGetDriverCount())
+	drivers := make([]Driver, 0, n)
+	for i := 0; i < n; i++ {
+		hndl := C.GDALGetDriver(C.int(i))
+		if hndl != nil {
+			drivers = append(drivers, Driver{majorObject{C.GDALMajorObjectH(hndl)}})
+		}
+	}
+	return drivers
+}
+
+// DataTypes returns the raster data types supported by the driver for creation
+func (drv Driver) DataTypes() []DataType {
+	names := strings.Fields(drv.Metadata("DMD_CREATIONDATATYPES"))
+	dtypes := make([]DataType, 0, len(names))
+	for _, name := range names {
+		cname := C.CString(name)
+		dtype := DataType(C.GDALGetDataTypeByName(cname))
+		C.free(unsafe.Pointer(cname))
+		if dtype != Unknown {
+			dtypes = append(dtypes, dtype)
+		}
+	}
+	return dtypes
+}
+
+// ValidateCreationOptions checks that the creation options passed with CreationOption
+// are supported by the driver, and returns an error describing the invalid ones otherwise.
+func (drv Driver) ValidateCreationOptions(opts ...ValidateCreationOptionsOption) error {
+	vo := validateCreationOptionsOpts{}
+	for _, opt := range opts {
+		opt.setValidateCreationOptionsOpt(&vo)
+	}
+	copts := sliceToCStringArray(vo.creation)
+	defer copts.free()
+	cgc := createCGOContext(nil, vo.errorHandler)
+	C.godalValidateCreationOptions(cgc.cPointer(), drv.handle(), copts.cPointer())
+	return cgc.close()
+}
+
 func getDriver(name string) (Driver, bool) {
 	cname := C.CString(string(name))
 	defer C.free(unsafe.Pointer(cname))
@@ -1461,6 +2256,9 @@ func Create(driver DriverName, name string, nBands int, dtype DataType, width, h
 		createOpts.cPointer())
 
 	if err := cgc.close(); err != nil {
+		if !drv.CanCreate() && drv.CanCreateCopy() {
+			return nil, fmt.Errorf("driver %s only supports CreateCopy, not Create (use Dataset.CreateCopy instead): %w", drvname, err)
+		}
 		return nil, err
 	}
 	return &Dataset{majorObject{C.GDALMajorObjectH(hndl)}}, nil
@@ -1500,6 +2298,82 @@ func CreateVector(driver DriverName, name string, opts ...DatasetCreateOption) (
 
 }
 
+func datasetFilesDriver(dfo datasetFilesOpts) (C.GDALDriverH, error) {
+	if dfo.driver == "" {
+		return nil, nil
+	}
+	if drv, ok := RasterDriver(dfo.driver); ok {
+		return drv.handle(), nil
+	}
+	if drv, ok := VectorDriver(dfo.driver); ok {
+		return drv.handle(), nil
+	}
+	return nil, fmt.Errorf("failed to get driver %s", dfo.driver)
+}
+
+// DeleteDataset wraps GDALDeleteDataset and deletes the named dataset along with all
+// its sidecar files (e.g. .ovr, .msk, .aux.xml, or the .shx/.dbf/.prj of a shapefile).
+// The dataset must not be opened. The driver is identified from name unless the
+// DriverName option is used.
+func DeleteDataset(name string, opts ...DatasetFilesOption) error {
+	dfo := datasetFilesOpts{}
+	for _, opt := range opts {
+		opt.setDatasetFilesOpt(&dfo)
+	}
+	drv, err := datasetFilesDriver(dfo)
+	if err != nil {
+		return err
+	}
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	cgc := createCGOContext(dfo.config, dfo.errorHandler)
+	C.godalDeleteDataset(cgc.cPointer(), drv, cname)
+	return cgc.close()
+}
+
+// RenameDataset wraps GDALRenameDataset and renames the oldName dataset to newName,
+// along with all its sidecar files. The dataset must not be opened. The driver is
+// identified from oldName unless the DriverName option is used.
+func RenameDataset(oldName, newName string, opts ...DatasetFilesOption) error {
+	dfo := datasetFilesOpts{}
+	for _, opt := range opts {
+		opt.setDatasetFilesOpt(&dfo)
+	}
+	drv, err := datasetFilesDriver(dfo)
+	if err != nil {
+		return err
+	}
+	coldName := C.CString(oldName)
+	defer C.free(unsafe.Pointer(coldName))
+	cnewName := C.CString(newName)
+	defer C.free(unsafe.Pointer(cnewName))
+	cgc := createCGOContext(dfo.config, dfo.errorHandler)
+	C.godalRenameDataset(cgc.cPointer(), drv, cnewName, coldName)
+	return cgc.close()
+}
+
+// CopyDatasetFiles wraps GDALCopyDatasetFiles and copies all the files of the srcName
+// dataset, including its sidecar files, to dstName. Contrary to Dataset.CreateCopy, the
+// files are copied as-is, without being decoded. The driver is identified from srcName
+// unless the DriverName option is used.
+func CopyDatasetFiles(srcName, dstName string, opts ...DatasetFilesOption) error {
+	dfo := datasetFilesOpts{}
+	for _, opt := range opts {
+		opt.setDatasetFilesOpt(&dfo)
+	}
+	drv, err := datasetFilesDriver(dfo)
+	if err != nil {
+		return err
+	}
+	csrcName := C.CString(srcName)
+	defer C.free(unsafe.Pointer(csrcName))
+	cdstName := C.CString(dstName)
+	defer C.free(unsafe.Pointer(cdstName))
+	cgc := createCGOContext(dfo.config, dfo.errorHandler)
+	C.godalCopyDatasetFiles(cgc.cPointer(), drv, cdstName, csrcName)
+	return cgc.close()
+}
+
 type majorObject struct {
 	cHandle C.GDALMajorObjectH
 }
@@ -1547,6 +2421,38 @@ func Open(name string, options ...OpenOption) (*Dataset, error) {
 	return &Dataset{majorObject{C.GDALMajorObjectH(retds)}}, nil
 }
 
+// IdentifyDriver calls GDALIdentifyDriverEx() to find the driver that would be used to
+// open name, without actually opening it. It returns false if no driver recognizes name.
+//
+// The RasterOnly, VectorOnly, Drivers and SiblingFiles options are honored as they are
+// by Open(), the other OpenOptions being ignored. As with Open(), name can be a /vsimem/
+// path or a path handled by RegisterVSIHandler.
+func IdentifyDriver(name string, options ...OpenOption) (Driver, bool, error) {
+	oopts := openOpts{
+		siblingFiles: []string{filepath.Base(name)},
+	}
+	for _, opt := range options {
+		opt.setOpenOpt(&oopts)
+	}
+	csiblings := sliceToCStringArray(oopts.siblingFiles)
+	cdrivers := sliceToCStringArray(oopts.drivers)
+	defer csiblings.free()
+	defer cdrivers.free()
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	cgc := createCGOContext(oopts.config, oopts.errorHandler)
+	hndl := C.godalIdentifyDriver(cgc.cPointer(), cname, C.uint(oopts.flags&C.GDAL_OF_KIND_MASK),
+		cdrivers.cPointer(), csiblings.cPointer())
+	if err := cgc.close(); err != nil {
+		return Driver{}, false, err
+	}
+	if hndl == nil {
+		return Driver{}, false, nil
+	}
+	return Driver{majorObject{C.GDALMajorObjectH(hndl)}}, true, nil
+}
+
 // Close releases the dataset
 func (ds *Dataset) Close(opts ...CloseOption) error {
 	co := &closeOpts{}
@@ -1783,6 +2689,10 @@ func bufferType(buffer interface{}) DataType {
 		return Float32
 	case []float64:
 		return Float64
+	case []int64:
+		return Int64
+	case []uint64:
+		return UInt64
 	case []complex64:
 		return CFloat32
 	case []complex128:
@@ -1822,6 +2732,12 @@ func cBuffer(buffer interface{}, minsize int) unsafe.Pointer {
 	case []float64:
 		sizecheck(len(buf))
 		return unsafe.Pointer(&buf[0])
+	case []int64:
+		sizecheck(len(buf))
+		return unsafe.Pointer(&buf[0])
+	case []uint64:
+		sizecheck(len(buf))
+		return unsafe.Pointer(&buf[0])
 	case []complex64:
 		sizecheck(len(buf))
 		return unsafe.Pointer(&buf[0])
@@ -1976,6 +2892,12 @@ func (rasterOnlyOpt) setOpenOpt(oo *openOpts) {
 }
 
 // SpatialRef is a wrapper around OGRSpatialReferenceH
+//
+// A SpatialRef is not safe for concurrent use: it must not be used by multiple goroutines
+// at the same time, including by read-only methods such as WKT() or IsSame(), nor while
+// it is being used to create a Transform. Use Clone() to obtain an independent copy for
+// each goroutine. The SpatialRefs returned by Dataset.SpatialRef(), Layer.SpatialRef()
+// or Geometry.SpatialRef() are owned by their parent object and share its restrictions.
 type SpatialRef struct {
 	handle  C.OGRSpatialReferenceH
 	isOwned bool
@@ -1988,7 +2910,21 @@ func (sr *SpatialRef) WKT(opts ...WKTExportOption) (string, error) {
 		o.setWKTExportOpt(wo)
 	}
 	cgc := createCGOContext(nil, wo.errorHandler)
-	cwkt := C.godalExportToWKT(cgc.cPointer(), sr.handle)
+	var cwkt *C.char
+	if wo.format == "" && !wo.multiline {
+		cwkt = C.godalExportToWKT(cgc.cPointer(), sr.handle)
+	} else {
+		options := []string{}
+		if wo.format != "" {
+			options = append(options, "FORMAT="+string(wo.format))
+		}
+		if wo.multiline {
+			options = append(options, "MULTILINE=YES")
+		}
+		copts := sliceToCStringArray(options)
+		defer copts.free()
+		cwkt = C.godalExportToWKTEx(cgc.cPointer(), sr.handle, copts.cPointer())
+	}
 	if err := cgc.close(); err != nil {
 		return "", err
 	}
@@ -1997,6 +2933,84 @@ func (sr *SpatialRef) WKT(opts ...WKTExportOption) (string, error) {
 	return wkt, nil
 }
 
+// PROJJSON returns the PROJJSON representation of the SpatialRef. The output is compact
+// unless the Multiline option is used. Requires GDAL >= 3.1.
+func (sr *SpatialRef) PROJJSON(opts ...PROJJSONExportOption) (string, error) {
+	po := &srPROJJSONOpts{}
+	for _, o := range opts {
+		o.setPROJJSONExportOpt(po)
+	}
+	options := []string{"MULTILINE=NO"}
+	if po.multiline {
+		options = []string{"MULTILINE=YES"}
+	}
+	copts := sliceToCStringArray(options)
+	defer copts.free()
+	cgc := createCGOContext(nil, po.errorHandler)
+	cjson := C.godalExportToPROJJSON(cgc.cPointer(), sr.handle, copts.cPointer())
+	if err := cgc.close(); err != nil {
+		return "", err
+	}
+	ret := C.GoString(cjson)
+	C.CPLFree(unsafe.Pointer(cjson))
+	return ret, nil
+}
+
+// Proj4 returns the PROJ string representation of the SpatialRef. This representation
+// is lossy and should only be used for display or logging purposes.
+func (sr *SpatialRef) Proj4(opts ...Proj4ExportOption) (string, error) {
+	po := &srProj4Opts{}
+	for _, o := range opts {
+		o.setProj4ExportOpt(po)
+	}
+	cgc := createCGOContext(nil, po.errorHandler)
+	cproj := C.godalExportToProj4(cgc.cPointer(), sr.handle)
+	if err := cgc.close(); err != nil {
+		return "", err
+	}
+	ret := C.GoString(cproj)
+	C.CPLFree(unsafe.Pointer(cproj))
+	return ret, nil
+}
+
+// Name returns the name of the SpatialRef, e.g. "WGS 84"
+func (sr *SpatialRef) Name() string {
+	return C.GoString(C.OSRGetName(sr.handle))
+}
+
+// LinearUnits returns the name of the linear units of the SpatialRef, and the factor
+// to convert them to meters
+func (sr *SpatialRef) LinearUnits() (string, float64) {
+	var cname *C.char
+	factor := C.OSRGetLinearUnits(sr.handle, &cname)
+	return C.GoString(cname), float64(factor)
+}
+
+// AngularUnits returns the name of the angular units of the SpatialRef, and the factor
+// to convert them to radians
+func (sr *SpatialRef) AngularUnits() (string, float64) {
+	var cname *C.char
+	factor := C.OSRGetAngularUnits(sr.handle, &cname)
+	return C.GoString(cname), float64(factor)
+}
+
+// AreaOfUse returns the area of use of the SpatialRef, and false if the SpatialRef does
+// not define one
+func (sr *SpatialRef) AreaOfUse() (AreaOfUse, bool) {
+	var w, s, e, n C.double
+	var cname *C.char
+	if C.OSRGetAreaOfUse(sr.handle, &w, &s, &e, &n, &cname) == 0 {
+		return AreaOfUse{}, false
+	}
+	return AreaOfUse{
+		Name:  C.GoString(cname),
+		West:  float64(w),
+		South: float64(s),
+		East:  float64(e),
+		North: float64(n),
+	}, true
+}
+
 // Close releases memory
 func (sr *SpatialRef) Close() {
 	if sr.handle == nil {
@@ -2015,14 +3029,14 @@ func (sr *SpatialRef) Close() {
 // "epsg:4326", "+proj=lonlat", wkt, wkt2 or projjson (as supported by
 // gdal's OSRCreateFromUserInput
 func NewSpatialRef(userInput string, opts ...CreateSpatialRefOption) (*SpatialRef, error) {
-	cso := &createSpatialRefOpts{}
+	cso := &createSpatialRefOpts{axisStrategy: DefaultAxisMappingStrategy()}
 	for _, o := range opts {
 		o.setCreateSpatialRefOpt(cso)
 	}
 	cstr := C.CString(userInput)
 	defer C.free(unsafe.Pointer(cstr))
 	cgc := createCGOContext(nil, cso.errorHandler)
-	hndl := C.godalCreateUserSpatialRef(cgc.cPointer(), (*C.char)(unsafe.Pointer(cstr)))
+	hndl := C.godalCreateUserSpatialRef(cgc.cPointer(), (*C.char)(unsafe.Pointer(cstr)), C.int(cso.axisStrategy))
 	if err := cgc.close(); err != nil {
 		return nil, err
 	}
@@ -2031,14 +3045,14 @@ func NewSpatialRef(userInput string, opts ...CreateSpatialRefOption) (*SpatialRe
 
 // NewSpatialRefFromWKT creates a SpatialRef from an opengis WKT description
 func NewSpatialRefFromWKT(wkt string, opts ...CreateSpatialRefOption) (*SpatialRef, error) {
-	cso := &createSpatialRefOpts{}
+	cso := &createSpatialRefOpts{axisStrategy: DefaultAxisMappingStrategy()}
 	for _, o := range opts {
 		o.setCreateSpatialRefOpt(cso)
 	}
 	cstr := C.CString(wkt)
 	defer C.free(unsafe.Pointer(cstr))
 	cgc := createCGOContext(nil, cso.errorHandler)
-	hndl := C.godalCreateWKTSpatialRef(cgc.cPointer(), (*C.char)(unsafe.Pointer(cstr)))
+	hndl := C.godalCreateWKTSpatialRef(cgc.cPointer(), (*C.char)(unsafe.Pointer(cstr)), C.int(cso.axisStrategy))
 	if err := cgc.close(); err != nil {
 		return nil, err
 	}
@@ -2047,71 +3061,676 @@ func NewSpatialRefFromWKT(wkt string, opts ...CreateSpatialRefOption) (*SpatialR
 
 // NewSpatialRefFromProj4 creates a SpatialRef from a proj4 string
 func NewSpatialRefFromProj4(proj string, opts ...CreateSpatialRefOption) (*SpatialRef, error) {
-	cso := &createSpatialRefOpts{}
+	cso := &createSpatialRefOpts{axisStrategy: DefaultAxisMappingStrategy()}
 	for _, o := range opts {
 		o.setCreateSpatialRefOpt(cso)
 	}
 	cstr := C.CString(proj)
 	defer C.free(unsafe.Pointer(cstr))
 	cgc := createCGOContext(nil, cso.errorHandler)
-	hndl := C.godalCreateProj4SpatialRef(cgc.cPointer(), (*C.char)(unsafe.Pointer(cstr)))
+	hndl := C.godalCreateProj4SpatialRef(cgc.cPointer(), (*C.char)(unsafe.Pointer(cstr)), C.int(cso.axisStrategy))
 	if err := cgc.close(); err != nil {
 		return nil, err
 	}
 	return &SpatialRef{handle: hndl, isOwned: true}, nil
 }
 
+// NewSpatialRefFromPROJJSON creates a SpatialRef from its PROJJSON representation
+func NewSpatialRefFromPROJJSON(projjson string, opts ...CreateSpatialRefOption) (*SpatialRef, error) {
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(projjson), &obj); err != nil {
+		return nil, fmt.Errorf("invalid PROJJSON: %w", err)
+	}
+	return NewSpatialRef(projjson, opts...)
+}
+
 // NewSpatialRefFromEPSG creates a SpatialRef from an epsg code
 func NewSpatialRefFromEPSG(code int, opts ...CreateSpatialRefOption) (*SpatialRef, error) {
-	cso := &createSpatialRefOpts{}
+	cso := &createSpatialRefOpts{axisStrategy: DefaultAxisMappingStrategy()}
 	for _, o := range opts {
 		o.setCreateSpatialRefOpt(cso)
 	}
 	cgc := createCGOContext(nil, cso.errorHandler)
-	hndl := C.godalCreateEPSGSpatialRef(cgc.cPointer(), C.int(code))
+	hndl := C.godalCreateEPSGSpatialRef(cgc.cPointer(), C.int(code), C.int(cso.axisStrategy))
+	if err := cgc.close(); err != nil {
+		return nil, err
+	}
+	return &SpatialRef{handle: hndl, isOwned: true}, nil
+}
+
+// NewCompoundSpatialRef creates a compound SpatialRef from a horizontal (geographic or
+// projected) and a vertical SpatialRef, e.g. EPSG:4326 and EPSG:5773 (EGM96 height).
+// If name is empty, the name is built from the names of the components. The resulting
+// SpatialRef is equivalent to the one that would be created by NewSpatialRef("EPSG:4326+5773").
+func NewCompoundSpatialRef(name string, horizontal, vertical *SpatialRef, opts ...CreateSpatialRefOption) (*SpatialRef, error) {
+	cso := &createSpatialRefOpts{axisStrategy: DefaultAxisMappingStrategy()}
+	for _, o := range opts {
+		o.setCreateSpatialRefOpt(cso)
+	}
+	if horizontal == nil || vertical == nil || horizontal.handle == nil || vertical.handle == nil {
+		return nil, fmt.Errorf("horizontal and vertical spatial references must not be nil")
+	}
+	if name == "" {
+		name = horizontal.Name() + " + " + vertical.Name()
+	}
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	cgc := createCGOContext(nil, cso.errorHandler)
+	hndl := C.godalCreateCompoundSpatialRef(cgc.cPointer(), cname, horizontal.handle, vertical.handle, C.int(cso.axisStrategy))
 	if err := cgc.close(); err != nil {
 		return nil, err
 	}
 	return &SpatialRef{handle: hndl, isOwned: true}, nil
 }
 
-// IsSame returns whether two SpatiaRefs describe the same projection.
-func (sr *SpatialRef) IsSame(other *SpatialRef) bool {
-	ret := C.OSRIsSame(sr.handle, other.handle)
+// AxisMappingStrategy defines how the axes of the coordinates passed to a Transform map to
+// the axes of its SpatialRefs, as defined by their authority
+type AxisMappingStrategy int
+
+const (
+	// TraditionalGISOrder uses longitude,latitude and easting,northing order, whatever the
+	// authority definition of the SpatialRef
+	TraditionalGISOrder AxisMappingStrategy = C.OAMS_TRADITIONAL_GIS_ORDER
+	// AuthorityCompliant uses the axis order defined by the authority, e.g. latitude,longitude
+	// for EPSG:4326
+	AuthorityCompliant AxisMappingStrategy = C.OAMS_AUTHORITY_COMPLIANT
+	// CustomAxisMapping is reported by SpatialRefs whose mapping has been set explicitly
+	CustomAxisMapping AxisMappingStrategy = C.OAMS_CUSTOM
+)
+
+var defaultAxisStrategy = int32(TraditionalGISOrder)
+
+// SetDefaultAxisMappingStrategy sets the AxisMappingStrategy applied to SpatialRefs created
+// with NewSpatialRef, NewSpatialRefFromEPSG, NewSpatialRefFromWKT or NewSpatialRefFromProj4
+// when no AxisMapping option is passed. The initial default is TraditionalGISOrder.
+func SetDefaultAxisMappingStrategy(strategy AxisMappingStrategy) {
+	atomic.StoreInt32(&defaultAxisStrategy, int32(strategy))
+}
+
+// DefaultAxisMappingStrategy returns the AxisMappingStrategy applied to newly created
+// SpatialRefs. See SetDefaultAxisMappingStrategy.
+func DefaultAxisMappingStrategy() AxisMappingStrategy {
+	return AxisMappingStrategy(atomic.LoadInt32(&defaultAxisStrategy))
+}
+
+// SetAxisMappingStrategy changes the AxisMappingStrategy of the SpatialRef. The change is not
+// applied to the Transforms that have already been created from this SpatialRef.
+func (sr *SpatialRef) SetAxisMappingStrategy(strategy AxisMappingStrategy) {
+	C.OSRSetAxisMappingStrategy(sr.handle, C.OSRAxisMappingStrategy(strategy))
+}
+
+// AxisMappingStrategy returns the AxisMappingStrategy of the SpatialRef
+func (sr *SpatialRef) AxisMappingStrategy() AxisMappingStrategy {
+	return AxisMappingStrategy(C.OSRGetAxisMappingStrategy(sr.handle))
+}
+
+// DataAxisToSRSAxisMapping returns, for each axis of the coordinates passed to a Transform,
+// the 1-based index of the corresponding axis of the SpatialRef as defined by its authority,
+// negated if the axis direction is reversed. E.g. EPSG:4326 returns [2,1] with the
+// TraditionalGISOrder strategy and [1,2] with the AuthorityCompliant one.
+func (sr *SpatialRef) DataAxisToSRSAxisMapping() []int {
+	var n C.int
+	cmapping := C.OSRGetDataAxisToSRSAxisMapping(sr.handle, &n)
+	if cmapping == nil || n == 0 {
+		return nil
+	}
+	mapping := (*[1 << 10]C.int)(unsafe.Pointer(cmapping))[:n:n]
+	ret := make([]int, n)
+	for i := range ret {
+		ret[i] = int(mapping[i])
+	}
+	return ret
+}
+
+// CoordinateEpoch returns the coordinate epoch of the SpatialRef, as a decimal year
+// (e.g. 2021.3), or 0 if not set. The coordinate epoch is only relevant for dynamic CRSs
+// such as ITRF2014 or WGS 84 (G2139). Always returns 0 with GDAL < 3.4.
+func (sr *SpatialRef) CoordinateEpoch() float64 {
+	return float64(C.godalGetCoordinateEpoch(sr.handle))
+}
+
+// SetCoordinateEpoch sets the coordinate epoch of the SpatialRef, as a decimal year. It is
+// used by Transforms involving a dynamic CRS when no per-point time is given, and is
+// stored along with the SpatialRef by the drivers supporting it (e.g. GTiff, GPKG,
+// FlatGeobuf). 0 clears the epoch. Requires GDAL >= 3.4.
+func (sr *SpatialRef) SetCoordinateEpoch(epoch float64, opts ...SetCoordinateEpochOption) error {
+	so := &setCoordinateEpochOpts{}
+	for _, o := range opts {
+		o.setSetCoordinateEpochOpt(so)
+	}
+	cgc := createCGOContext(nil, so.errorHandler)
+	C.godalSetCoordinateEpoch(cgc.cPointer(), sr.handle, C.double(epoch))
+	return cgc.close()
+}
+
+// longitudeFirst returns whether the first coordinate of geographic points is the longitude
+func (sr *SpatialRef) longitudeFirst() bool {
+	return C.godalLongitudeFirst(sr.handle) != 0
+}
+
+// IsSame returns whether two SpatiaRefs describe the same projection. By default, names
+// and the axis order of geographic SpatialRefs are ignored, but AxisMappingStrategies and
+// coordinate epochs are compared. See IsSameOption to change these criteria.
+//
+// IsSameOptions other than the default ones require GDAL >= 3.1 and are ignored otherwise.
+func (sr *SpatialRef) IsSame(other *SpatialRef, opts ...IsSameOption) bool {
+	io := isSameOpts{criterion: SameEquivalentExceptAxisOrder}
+	for _, o := range opts {
+		o.setIsSameOpt(&io)
+	}
+	copts := sliceToCStringArray(io.options())
+	defer copts.free()
+	ret := C.godalIsSameSpatialRef(sr.handle, other.handle, copts.cPointer())
 	return ret != 0
 }
 
+// Clone returns a copy of the SpatialRef, including its AxisMappingStrategy and coordinate
+// epoch. The returned SpatialRef must be closed with Close().
+func (sr *SpatialRef) Clone() *SpatialRef {
+	return &SpatialRef{handle: C.OSRClone(sr.handle), isOwned: true}
+}
+
+// CloneGeogCS returns the geographic SpatialRef underlying the SpatialRef, e.g. EPSG:4326
+// for EPSG:32631 or EPSG:4326+5773. The AxisMappingStrategy of the SpatialRef is kept.
+// The returned SpatialRef must be closed with Close().
+func (sr *SpatialRef) CloneGeogCS(opts ...CloneGeogCSOption) (*SpatialRef, error) {
+	co := &cloneGeogCSOpts{}
+	for _, o := range opts {
+		o.setCloneGeogCSOpt(co)
+	}
+	cgc := createCGOContext(nil, co.errorHandler)
+	hndl := C.godalCloneGeogCS(cgc.cPointer(), sr.handle)
+	if err := cgc.close(); err != nil {
+		return nil, err
+	}
+	return &SpatialRef{handle: hndl, isOwned: true}, nil
+}
+
+// StripVertical removes the vertical component of a compound SpatialRef, and converts a 3D
+// geographic or projected SpatialRef to its 2D counterpart. It is a no-op on other
+// SpatialRefs. Requires GDAL >= 3.6.
+func (sr *SpatialRef) StripVertical(opts ...StripVerticalOption) error {
+	so := &stripVerticalOpts{}
+	for _, o := range opts {
+		o.setStripVerticalOpt(so)
+	}
+	cgc := createCGOContext(nil, so.errorHandler)
+	C.godalStripVertical(cgc.cPointer(), sr.handle)
+	return cgc.close()
+}
+
 // Transform transforms coordinates from one SpatialRef to another
+//
+// A Transform is not safe for concurrent use: it must not be used by multiple goroutines
+// at the same time. Creating a Transform is costly as it requires PROJ to look up and
+// instantiate the coordinate operation, so concurrent workers should either use a Clone()
+// of a common Transform each, or share a TransformPool.
 type Transform struct {
 	handle C.OGRCoordinateTransformationH
 	dst    C.OGRSpatialReferenceH //TODO: refcounting/freeing on this?
+	opts   trnOpts
+}
+
+// cOptions converts the options to their C counterpart. The returned function must be called
+// to release the allocated memory
+func (to *trnOpts) cOptions() (*C.godalCTOptions, func()) {
+	copts := (*C.godalCTOptions)(C.calloc(1, C.size_t(unsafe.Sizeof(C.godalCTOptions{}))))
+	if to.aoi != nil {
+		copts.hasAreaOfInterest = 1
+		copts.west, copts.south = C.double(to.aoi[0]), C.double(to.aoi[1])
+		copts.east, copts.north = C.double(to.aoi[2]), C.double(to.aoi[3])
+	}
+	copts.desiredAccuracy = C.double(to.accuracy)
+	copts.ballpark = -1
+	if to.ballpark != nil {
+		copts.ballpark = 0
+		if *to.ballpark {
+			copts.ballpark = 1
+		}
+	}
+	if to.pipeline != "" {
+		copts.operation = C.CString(to.pipeline)
+		if to.reverse {
+			copts.reverse = 1
+		}
+	}
+	return copts, func() {
+		C.free(unsafe.Pointer(copts.operation))
+		C.free(unsafe.Pointer(copts))
+	}
 }
 
-// NewTransform creates a transformation object from src to dst
+// NewTransform creates a transformation object from src to dst. src and dst may be nil
+// if the ProjPipeline option is used.
 func NewTransform(src, dst *SpatialRef, opts ...TransformOption) (*Transform, error) {
 	to := &trnOpts{}
 	for _, o := range opts {
 		o.setTransformOpt(to)
 	}
+	var csrc, cdst C.OGRSpatialReferenceH
+	if src != nil {
+		csrc = src.handle
+	}
+	if dst != nil {
+		cdst = dst.handle
+	}
 	cgc := createCGOContext(nil, to.errorHandler)
-	hndl := C.godalNewCoordinateTransformation(cgc.cPointer(), src.handle, dst.handle)
+	var hndl C.OGRCoordinateTransformationH
+	if to.aoi == nil && to.accuracy == 0 && to.ballpark == nil && to.pipeline == "" {
+		hndl = C.godalNewCoordinateTransformation(cgc.cPointer(), csrc, cdst)
+	} else {
+		copts, free := to.cOptions()
+		defer free()
+		hndl = C.godalNewCoordinateTransformationEx(cgc.cPointer(), csrc, cdst, copts)
+	}
+	if err := cgc.close(); err != nil {
+		return nil, err
+	}
+	return &Transform{handle: hndl, dst: cdst, opts: *to}, nil
+}
+
+// coordinateOperations lists the candidate operations from src to dst. If
+// discardMissingGrids is set, the operations requiring unavailable grids are left out as
+// when PROJ instantiates a transformation.
+func coordinateOperations(src, dst C.OGRSpatialReferenceH, to *trnOpts, discardMissingGrids bool) ([]CoordinateOperation, error) {
+	copts, free := to.cOptions()
+	defer free()
+	discard := C.int(0)
+	if discardMissingGrids {
+		discard = 1
+	}
+	var count C.int
+	cgc := createCGOContext(nil, to.errorHandler)
+	cops := C.godalCoordinateOperations(cgc.cPointer(), src, dst, copts, discard, &count)
+	if err := cgc.close(); err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return []CoordinateOperation{}, nil
+	}
+	defer C.godalFreeCoordinateOperations(cops, count)
+	sops := (*[1 << 20]C.godalCoordOp)(unsafe.Pointer(cops))[:count:count]
+	ret := make([]CoordinateOperation, count)
+	for i, cop := range sops {
+		ret[i] = CoordinateOperation{
+			Name:         C.GoString(cop.name),
+			Proj:         C.GoString(cop.proj),
+			Accuracy:     float64(cop.accuracy),
+			Instantiable: cop.instantiable != 0,
+		}
+		if cop.hasAreaOfUse != 0 {
+			ret[i].AreaOfUse = &AreaOfUse{
+				Name:  C.GoString(cop.areaName),
+				West:  float64(cop.west),
+				South: float64(cop.south),
+				East:  float64(cop.east),
+				North: float64(cop.north),
+			}
+		}
+	}
+	return ret, nil
+}
+
+// CoordinateOperations lists the candidate coordinate operations between src and dst, as
+// returned by PROJ, sorted from the most to the least relevant. This includes operations
+// requiring grids that are not available (see CoordinateOperation.Instantiable).
+//
+// The operations are looked up with the PROJ settings (search paths, network access) of
+// GDAL.
+func CoordinateOperations(src, dst *SpatialRef, opts ...CoordinateOperationsOption) ([]CoordinateOperation, error) {
+	to := &trnOpts{}
+	for _, o := range opts {
+		o.setCoordinateOperationsOpt(to)
+	}
+	if src == nil || dst == nil || src.handle == nil || dst.handle == nil {
+		return nil, fmt.Errorf("source and destination spatial references must not be nil")
+	}
+	return coordinateOperations(src.handle, dst.handle, to, false)
+}
+
+// EstimatedOperation returns a best-effort estimate of the coordinate operation used by the
+// Transform. If the Transform was created with the ProjPipeline option, the forced
+// operation is returned. Otherwise it is the first candidate PROJ returns for the options
+// the Transform was created with, once the candidates requiring unavailable grids are
+// discarded as GDAL/PROJ do when instantiating the transformation. As PROJ selects the
+// operation point by point amongst these candidates (e.g. depending on their areas of
+// use), the returned operation may not be the one actually applied to a given point.
+func (trn *Transform) EstimatedOperation() (CoordinateOperation, error) {
+	opts := trn.opts
+	opts.errorHandler = nil
+	var src, dst C.OGRSpatialReferenceH
+	if opts.pipeline == "" {
+		src, dst = C.godalTransformSourceCS(trn.handle), C.godalTransformTargetCS(trn.handle)
+		if src == nil || dst == nil {
+			return CoordinateOperation{}, fmt.Errorf("transform has no source or target spatial reference")
+		}
+	}
+	ops, err := coordinateOperations(src, dst, &opts, true)
+	if err != nil {
+		return CoordinateOperation{}, err
+	}
+	for _, op := range ops {
+		if op.Instantiable {
+			return op, nil
+		}
+	}
+	return CoordinateOperation{}, fmt.Errorf("no instantiable coordinate operation found")
+}
+
+// Close releases the Transform object
+func (trn *Transform) Close() {
+	if trn.handle == nil {
+		return
+		//panic("transform already closed")
+	}
+	C.OCTDestroyCoordinateTransformation(trn.handle)
+	trn.handle = nil
+}
+
+func (trn *Transform) clone(eh ErrorHandler) (*Transform, error) {
+	cgc := createCGOContext(nil, eh)
+	hndl := C.godalCloneCoordinateTransformation(cgc.cPointer(), trn.handle)
+	if err := cgc.close(); err != nil {
+		return nil, err
+	}
+	ret := &Transform{handle: hndl, dst: C.godalTransformTargetCS(hndl), opts: trn.opts}
+	return ret, nil
+}
+
+// Clone returns an independent copy of the Transform, which is much cheaper than creating
+// a new Transform with NewTransform. The returned Transform does not depend on the original
+// one and must be closed with Close(). Clone must not be called while the Transform is being
+// used by another goroutine. Requires GDAL >= 3.1.
+func (trn *Transform) Clone(opts ...CloneTransformOption) (*Transform, error) {
+	co := &cloneTransformOpts{}
+	for _, o := range opts {
+		o.setCloneTransformOpt(co)
+	}
+	return trn.clone(co.errorHandler)
+}
+
+// TransformPool is a pool of Transforms sharing the same definition, that can be used
+// concurrently by multiple goroutines. Transforms are obtained from the pool with Get(),
+// and given back with Put() once they are not used anymore. New Transforms are cloned from
+// the initial one when the pool is empty. At most GOMAXPROCS idle Transforms are kept by
+// the pool, the ones given back in excess being closed.
+type TransformPool struct {
+	mu           sync.Mutex
+	proto        *Transform
+	idle         []*Transform
+	maxIdle      int
+	errorHandler ErrorHandler
+	// cloneMu serializes the clones of proto, and prevents proto from being closed while
+	// it is being cloned
+	cloneMu sync.Mutex
+}
+
+// NewTransformPool creates a TransformPool of Transforms from src to dst, as would be
+// created by NewTransform. The pool must be released with Close(). Requires GDAL >= 3.1.
+func NewTransformPool(src, dst *SpatialRef, opts ...TransformOption) (*TransformPool, error) {
+	proto, err := NewTransform(src, dst, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &TransformPool{
+		proto:        proto,
+		maxIdle:      runtime.GOMAXPROCS(0),
+		errorHandler: proto.opts.errorHandler,
+	}, nil
+}
+
+// Get returns a Transform for the exclusive use of the caller, until it is given back to
+// the pool with Put(). It must not be closed by the caller.
+func (tp *TransformPool) Get() (*Transform, error) {
+	tp.mu.Lock()
+	if tp.proto == nil {
+		tp.mu.Unlock()
+		return nil, fmt.Errorf("transform pool is closed")
+	}
+	if n := len(tp.idle); n > 0 {
+		trn := tp.idle[n-1]
+		tp.idle = tp.idle[:n-1]
+		tp.mu.Unlock()
+		return trn, nil
+	}
+	proto := tp.proto
+	tp.mu.Unlock()
+
+	// clone without holding tp.mu so that Get and Put calls served from the idle
+	// Transforms are not blocked
+	tp.cloneMu.Lock()
+	defer tp.cloneMu.Unlock()
+	tp.mu.Lock()
+	closed := tp.proto == nil
+	tp.mu.Unlock()
+	if closed {
+		return nil, fmt.Errorf("transform pool is closed")
+	}
+	return proto.clone(tp.errorHandler)
+}
+
+// Put gives back a Transform obtained with Get() to the pool. The Transform must not
+// be used by the caller afterwards.
+func (tp *TransformPool) Put(trn *Transform) {
+	tp.mu.Lock()
+	if tp.proto != nil && len(tp.idle) < tp.maxIdle {
+		tp.idle = append(tp.idle, trn)
+		trn = nil
+	}
+	tp.mu.Unlock()
+	if trn != nil {
+		trn.Close()
+	}
+}
+
+// Close releases the Transforms held by the pool. Transforms that are in use at the time
+// Close is called are released when given back with Put().
+func (tp *TransformPool) Close() {
+	tp.mu.Lock()
+	proto, idle := tp.proto, tp.idle
+	tp.proto, tp.idle = nil, nil
+	tp.mu.Unlock()
+	if proto == nil {
+		return
+	}
+	for _, trn := range idle {
+		trn.Close()
+	}
+	tp.cloneMu.Lock()
+	proto.Close()
+	tp.cloneMu.Unlock()
+}
+
+// TransformEx reprojects points in place
+//
+// x and y may not be nil and must be of the same length
+//
+// z may be nil, or of the same length as x and y. When both SpatialRefs are 3D (see
+// SpatialRef.Is3D, SpatialRef.PromoteTo3D and NewCompoundSpatialRef), z is converted
+// as well, e.g. from ellipsoidal heights (EPSG:4979) to orthometric heights (EPSG:4326+5773).
+// Such conversions require the corresponding geoid grid to be available to PROJ, either
+// installed locally or fetched with PROJ_NETWORK=ON.
+//
+// successful may be nil or of the same length as x and y. If non nil, it will contain
+// true or false depending on wether the corresponding point succeeded transformation or not.
+//
+// See TransformXY, TransformXYZ or TransformInterleaved for versions of this function that
+// do not allocate memory.
+//
+// TODO: create a Transform() method that accepts z and successful as options
+func (trn *Transform) TransformEx(x []float64, y []float64, z []float64, successful []bool) error {
+	cx := make([]C.double, len(x))
+	cy := make([]C.double, len(x))
+	pcx, pcy := (*C.double)(unsafe.Pointer(&cx[0])), (*C.double)(unsafe.Pointer(&cy[0]))
+	pcz := (*C.double)(nil)
+	pcs := (*C.int)(nil)
+	var cz []C.double
+	var cs []C.int
+	if len(z) > 0 {
+		cz = make([]C.double, len(x))
+		pcz = (*C.double)(unsafe.Pointer(&cz[0]))
+	}
+	if len(successful) > 0 {
+		cs = make([]C.int, len(x))
+		pcs = (*C.int)(unsafe.Pointer(&cs[0]))
+	}
+	for i := range x {
+		cx[i] = C.double(x[i])
+		cy[i] = C.double(y[i])
+		if cz != nil {
+			cz[i] = C.double(z[i])
+		}
+	}
+	ret := C.OCTTransformEx(trn.handle, C.int(len(x)), pcx, pcy, pcz, pcs)
+	for i := range x {
+		x[i] = float64(cx[i])
+		y[i] = float64(cy[i])
+		if cz != nil {
+			z[i] = float64(cz[i])
+		}
+		if cs != nil {
+			if cs[i] > 0 {
+				successful[i] = true
+			} else {
+				successful[i] = false
+			}
+		}
+	}
+	if ret == 0 {
+		return fmt.Errorf("some or all points failed to transform")
+	}
+	return nil
+}
+
+// transformInterleaved reprojects the n points of dim coordinates stored from coords
+func (trn *Transform) transformInterleaved(coords *float64, n, dim int, successful []bool) error {
+	if successful != nil && len(successful) != n {
+		return fmt.Errorf("successful must contain one value per point")
+	}
+	if n == 0 {
+		return nil
+	}
+	pcs := (*C.uchar)(nil)
+	if successful != nil {
+		pcs = (*C.uchar)(unsafe.Pointer(&successful[0]))
+	}
+	ret := C.godalTransformInterleaved(trn.handle, C.int(n), C.int(dim), (*C.double)(unsafe.Pointer(coords)), pcs)
+	if ret == 0 {
+		return fmt.Errorf("some or all points failed to transform")
+	}
+	return nil
+}
+
+// TransformInterleaved reprojects in place points stored as consecutive tuples of dim
+// coordinates, i.e. x0,y0,x1,y1,... for dim=2 or x0,y0,z0,x1,y1,z1,... for dim=3. When dim
+// is greater than 3, the remaining coordinates of each tuple (e.g. m) are left untouched.
+// Contrary to TransformEx, no memory is allocated.
+//
+// successful may be nil or contain one value per point. If non nil, it will contain
+// true or false depending on wether the corresponding point succeeded transformation or not.
+func (trn *Transform) TransformInterleaved(coords []float64, dim int, successful []bool) error {
+	if dim < 2 {
+		return fmt.Errorf("dim must be at least 2")
+	}
+	if len(coords)%dim != 0 {
+		return fmt.Errorf("coords length %d is not a multiple of %d", len(coords), dim)
+	}
+	if len(coords) == 0 {
+		return trn.transformInterleaved(nil, 0, dim, successful)
+	}
+	return trn.transformInterleaved(&coords[0], len(coords)/dim, dim, successful)
+}
+
+// TransformXY reprojects 2D points in place. Contrary to TransformEx, no memory is allocated.
+//
+// successful may be nil or of the same length as points. If non nil, it will contain
+// true or false depending on wether the corresponding point succeeded transformation or not.
+func (trn *Transform) TransformXY(points [][2]float64, successful []bool) error {
+	if len(points) == 0 {
+		return trn.transformInterleaved(nil, 0, 2, successful)
+	}
+	return trn.transformInterleaved(&points[0][0], len(points), 2, successful)
+}
+
+// TransformXYZ reprojects 3D points in place. Contrary to TransformEx, no memory is allocated.
+//
+// successful may be nil or of the same length as points. If non nil, it will contain
+// true or false depending on wether the corresponding point succeeded transformation or not.
+func (trn *Transform) TransformXYZ(points [][3]float64, successful []bool) error {
+	if len(points) == 0 {
+		return trn.transformInterleaved(nil, 0, 3, successful)
+	}
+	return trn.transformInterleaved(&points[0][0], len(points), 3, successful)
+}
+
+// transformBounds reprojects bounds with OCTTransformBounds, reporting errors to the
+// ErrorHandler the transform was created with. It returns false if the GDAL version does
+// not provide it
+func (trn *Transform) transformBounds(bnds [4]float64, densifyPts int) ([4]float64, bool, error) {
+	cbnds := [4]C.double{C.double(bnds[0]), C.double(bnds[1]), C.double(bnds[2]), C.double(bnds[3])}
+	cgc := createCGOContext(nil, trn.opts.errorHandler)
+	ok := C.godalTransformBounds(cgc.cPointer(), trn.handle, &cbnds[0], C.int(densifyPts))
+	if err := cgc.close(); err != nil {
+		return bnds, true, err
+	}
+	if ok == 0 {
+		return bnds, false, nil
+	}
+	return [4]float64{float64(cbnds[0]), float64(cbnds[1]), float64(cbnds[2]), float64(cbnds[3])}, true, nil
+}
+
+// Transformer maps pixel/line coordinates of a source dataset to pixel/line (or
+// georeferenced) coordinates of a destination dataset, using the same machinery as
+// gdalwarp: geotransforms, GCP polynomials or thin plate splines, RPCs or geolocation
+// arrays.
+type Transformer struct {
+	handle unsafe.Pointer
+}
+
+// NewTransformer creates a Transformer from the pixel/line coordinates of src to the
+// pixel/line coordinates of dst. src or dst may be nil, in which case the corresponding
+// side of the transformation is expressed in georeferenced coordinates (i.e. in the
+// SRS of the dataset, or in the SRS given by the SRC_SRS/DST_SRS options).
+//
+// The transformation method and its parameters are selected with TransformerOption,
+// which accepts the same KEY=VALUE pairs as gdalwarp's -to switch, e.g.
+// METHOD=RPC, RPC_DEM=dem.tif, METHOD=GCP_TPS, METHOD=GEOLOC_ARRAY, DST_SRS=EPSG:4326...
+func NewTransformer(src, dst *Dataset, opts ...NewTransformerOption) (*Transformer, error) {
+	to := newTransformerOpts{}
+	for _, o := range opts {
+		o.setNewTransformerOpt(&to)
+	}
+	var csrc, cdst C.GDALDatasetH
+	if src != nil {
+		csrc = src.handle()
+	}
+	if dst != nil {
+		cdst = dst.handle()
+	}
+	copts := sliceToCStringArray(to.options)
+	defer copts.free()
+	cgc := createCGOContext(to.config, to.errorHandler)
+	hndl := C.godalCreateGenImgProjTransformer(cgc.cPointer(), csrc, cdst, copts.cPointer())
 	if err := cgc.close(); err != nil {
+		if hndl != nil {
+			C.GDALDestroyGenImgProjTransformer(hndl)
+		}
 		return nil, err
 	}
-	return &Transform{handle: hndl, dst: dst.handle}, nil
+	return &Transformer{handle: hndl}, nil
 }
 
-// Close releases the Transform object
-func (trn *Transform) Close() {
-	if trn.handle == nil {
+// Close releases the Transformer object
+func (tr *Transformer) Close() {
+	if tr.handle == nil {
 		return
-		//panic("transform already closed")
 	}
-	C.OCTDestroyCoordinateTransformation(trn.handle)
-	trn.handle = nil
+	C.GDALDestroyGenImgProjTransformer(tr.handle)
+	tr.handle = nil
 }
 
-// TransformEx reprojects points in place
+// TransformPoints transforms points in place from the source pixel/line coordinates to
+// the destination coordinates.
 //
 // x and y may not be nil and must be of the same length
 //
@@ -2119,47 +3738,94 @@ func (trn *Transform) Close() {
 //
 // successful may be nil or of the same length as x and y. If non nil, it will contain
 // true or false depending on wether the corresponding point succeeded transformation or not.
-//
-// TODO: create a version of this function that accepts *C.double to avoid allocs?
-// TODO: create a Transform() method that accepts z and successful as options
-func (trn *Transform) TransformEx(x []float64, y []float64, z []float64, successful []bool) error {
-	cx := make([]C.double, len(x))
-	cy := make([]C.double, len(x))
-	pcx, pcy := (*C.double)(unsafe.Pointer(&cx[0])), (*C.double)(unsafe.Pointer(&cy[0]))
-	pcz := (*C.double)(nil)
-	pcs := (*C.int)(nil)
-	var cz []C.double
-	var cs []C.int
-	if len(z) > 0 {
-		cz = make([]C.double, len(x))
-		pcz = (*C.double)(unsafe.Pointer(&cz[0]))
+func (tr *Transformer) TransformPoints(x, y, z []float64, successful []bool, opts ...TransformPointsOption) error {
+	return tr.transformPoints(false, x, y, z, successful, opts)
+}
+
+// InverseTransformPoints transforms points in place from the destination coordinates
+// back to the source pixel/line coordinates. Arguments are the same as for TransformPoints.
+func (tr *Transformer) InverseTransformPoints(x, y, z []float64, successful []bool, opts ...TransformPointsOption) error {
+	return tr.transformPoints(true, x, y, z, successful, opts)
+}
+
+func (tr *Transformer) transformPoints(inverse bool, x, y, z []float64, successful []bool, opts []TransformPointsOption) error {
+	tpo := transformPointsOpts{}
+	for _, o := range opts {
+		o.setTransformPointsOpt(&tpo)
 	}
-	if len(successful) > 0 {
-		cs = make([]C.int, len(x))
-		pcs = (*C.int)(unsafe.Pointer(&cs[0]))
+	if len(x) != len(y) || (z != nil && len(z) != len(x)) || (successful != nil && len(successful) != len(x)) {
+		return fmt.Errorf("x, y, z and successful must be of the same length")
 	}
-	for i := range x {
-		cx[i] = C.double(x[i])
-		cy[i] = C.double(y[i])
-		if cz != nil {
-			cz[i] = C.double(z[i])
-		}
+	if len(x) == 0 {
+		return nil
 	}
-	ret := C.OCTTransformEx(trn.handle, C.int(len(x)), pcx, pcy, pcz, pcs)
-	for i := range x {
-		x[i] = float64(cx[i])
-		y[i] = float64(cy[i])
-		if cz != nil {
-			z[i] = float64(cz[i])
+	if z == nil {
+		z = make([]float64, len(x))
+	}
+	cs := make([]C.int, len(x))
+	dstToSrc := 0
+	if inverse {
+		dstToSrc = 1
+	}
+	cgc := createCGOContext(nil, tpo.errorHandler)
+	C.godalGenImgProjTransform(cgc.cPointer(), tr.handle, C.int(dstToSrc), C.int(len(x)),
+		(*C.double)(unsafe.Pointer(&x[0])), (*C.double)(unsafe.Pointer(&y[0])),
+		(*C.double)(unsafe.Pointer(&z[0])), (*C.int)(unsafe.Pointer(&cs[0])))
+	if err := cgc.close(); err != nil {
+		return err
+	}
+	failed := 0
+	for i := range cs {
+		if successful != nil {
+			successful[i] = cs[i] != 0
 		}
-		if cs != nil {
-			if cs[i] > 0 {
-				successful[i] = true
-			} else {
-				successful[i] = false
-			}
+		if cs[i] == 0 {
+			failed++
 		}
 	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d points failed to transform", failed, len(x))
+	}
+	return nil
+}
+
+// TransformEx4D reprojects points in place, taking into account the time of each point
+// for time-dependent transformations (e.g. between ITRF2014 and ETRF2000).
+//
+// x and y may not be nil and must be of the same length
+//
+// z and t may be nil, or of the same length as x and y. t contains the coordinate epochs
+// of the points as decimal years. If t is nil, the coordinate epochs of the source
+// and destination SpatialRefs are used.
+//
+// successful may be nil or of the same length as x and y. If non nil, it will contain
+// true or false depending on wether the corresponding point succeeded transformation or not.
+func (trn *Transform) TransformEx4D(x, y, z, t []float64, successful []bool) error {
+	if len(y) != len(x) || (z != nil && len(z) != len(x)) || (t != nil && len(t) != len(x)) ||
+		(successful != nil && len(successful) != len(x)) {
+		return fmt.Errorf("x, y, z, t and successful must be of the same length")
+	}
+	if len(x) == 0 {
+		return nil
+	}
+	pcz, pct := (*C.double)(nil), (*C.double)(nil)
+	if z != nil {
+		pcz = (*C.double)(unsafe.Pointer(&z[0]))
+	}
+	if t != nil {
+		pct = (*C.double)(unsafe.Pointer(&t[0]))
+	}
+	var cs []C.int
+	pcs := (*C.int)(nil)
+	if successful != nil {
+		cs = make([]C.int, len(x))
+		pcs = (*C.int)(unsafe.Pointer(&cs[0]))
+	}
+	ret := C.OCTTransform4D(trn.handle, C.int(len(x)), (*C.double)(unsafe.Pointer(&x[0])),
+		(*C.double)(unsafe.Pointer(&y[0])), pcz, pct, pcs)
+	for i := range cs {
+		successful[i] = cs[i] != 0
+	}
 	if ret == 0 {
 		return fmt.Errorf("some or all points failed to transform")
 	}
@@ -2184,6 +3850,69 @@ func (sr *SpatialRef) Projected() bool {
 	return ret != 0
 }
 
+// IsCompound returns wether the SpatialRef is a compound (horizontal + vertical) SpatialRef
+func (sr *SpatialRef) IsCompound() bool {
+	ret := C.OSRIsCompound(sr.handle)
+	return ret != 0
+}
+
+// IsVertical returns wether the SpatialRef is a vertical SpatialRef, or a compound
+// SpatialRef with a vertical component
+func (sr *SpatialRef) IsVertical() bool {
+	ret := C.OSRIsVertical(sr.handle)
+	return ret != 0
+}
+
+// IsGeocentric returns wether the SpatialRef is geocentric
+func (sr *SpatialRef) IsGeocentric() bool {
+	ret := C.OSRIsGeocentric(sr.handle)
+	return ret != 0
+}
+
+// Is3D returns wether the SpatialRef has a vertical axis, i.e. is geocentric, compound,
+// or a 3D geographic or projected SpatialRef. With GDAL < 3.1, only geocentric and compound
+// SpatialRefs are reported as 3D.
+func (sr *SpatialRef) Is3D() bool {
+	return C.godalSpatialRefIs3D(sr.handle) != 0
+}
+
+// PromoteTo3D converts a 2D geographic or projected SpatialRef to its 3D counterpart, with
+// an ellipsoidal height as vertical axis, e.g. EPSG:4326 to EPSG:4979. It is a no-op on
+// SpatialRefs that are already 3D. If name is empty, the name of the SpatialRef is kept.
+// Requires GDAL >= 3.1.
+func (sr *SpatialRef) PromoteTo3D(name string, opts ...PromoteTo3DOption) error {
+	po := &promoteTo3DOpts{}
+	for _, o := range opts {
+		o.setPromoteTo3DOpt(po)
+	}
+	var cname *C.char
+	if name != "" {
+		cname = C.CString(name)
+		defer C.free(unsafe.Pointer(cname))
+	}
+	cgc := createCGOContext(nil, po.errorHandler)
+	C.godalPromoteTo3D(cgc.cPointer(), sr.handle, cname)
+	return cgc.close()
+}
+
+// DemoteTo2D converts a 3D geographic or projected SpatialRef to its 2D counterpart, e.g.
+// EPSG:4979 to EPSG:4326. It is a no-op on SpatialRefs that are already 2D. If name is empty,
+// the name of the SpatialRef is kept. Requires GDAL >= 3.2.
+func (sr *SpatialRef) DemoteTo2D(name string, opts ...DemoteTo2DOption) error {
+	do := &demoteTo2DOpts{}
+	for _, o := range opts {
+		o.setDemoteTo2DOpt(do)
+	}
+	var cname *C.char
+	if name != "" {
+		cname = C.CString(name)
+		defer C.free(unsafe.Pointer(cname))
+	}
+	cgc := createCGOContext(nil, do.errorHandler)
+	C.godalDemoteTo2D(cgc.cPointer(), sr.handle, cname)
+	return cgc.close()
+}
+
 // SemiMajor returns the SpatialRef's Semi Major Axis
 func (sr *SpatialRef) SemiMajor() (float64, error) {
 	var err C.int
@@ -2250,6 +3979,133 @@ func (sr *SpatialRef) AuthorityCode(target string) string {
 	return ""
 }
 
+// CRSType is the type of a CRS listed in the PROJ database
+type CRSType int
+
+const (
+	// CRSGeographic2D is a geographic CRS with latitude and longitude axes
+	CRSGeographic2D CRSType = C.OSR_CRS_TYPE_GEOGRAPHIC_2D
+	// CRSGeographic3D is a geographic CRS with latitude, longitude and ellipsoidal height axes
+	CRSGeographic3D CRSType = C.OSR_CRS_TYPE_GEOGRAPHIC_3D
+	// CRSGeocentric is a geocentric CRS
+	CRSGeocentric CRSType = C.OSR_CRS_TYPE_GEOCENTRIC
+	// CRSProjected is a projected CRS
+	CRSProjected CRSType = C.OSR_CRS_TYPE_PROJECTED
+	// CRSVertical is a vertical CRS
+	CRSVertical CRSType = C.OSR_CRS_TYPE_VERTICAL
+	// CRSCompound is a compound CRS
+	CRSCompound CRSType = C.OSR_CRS_TYPE_COMPOUND
+	// CRSOther is any other type of CRS
+	CRSOther CRSType = C.OSR_CRS_TYPE_OTHER
+)
+
+// String implements Stringer
+func (t CRSType) String() string {
+	switch t {
+	case CRSGeographic2D:
+		return "Geographic2D"
+	case CRSGeographic3D:
+		return "Geographic3D"
+	case CRSGeocentric:
+		return "Geocentric"
+	case CRSProjected:
+		return "Projected"
+	case CRSVertical:
+		return "Vertical"
+	case CRSCompound:
+		return "Compound"
+	default:
+		return "Other"
+	}
+}
+
+// ListCRS lists the CRSs of the PROJ database, optionally filtered by authority, name,
+// type or area of use (see ListCRSOption). Deprecated CRSs are included and flagged as such.
+func ListCRS(opts ...ListCRSOption) ([]CRSInfo, error) {
+	lo := &listCRSOpts{}
+	for _, o := range opts {
+		o.setListCRSOpt(lo)
+	}
+	var cauth *C.char
+	if lo.authority != "" {
+		cauth = C.CString(lo.authority)
+		defer C.free(unsafe.Pointer(cauth))
+	}
+	var count C.int
+	cgc := createCGOContext(nil, lo.errorHandler)
+	clist := C.godalGetCRSInfoList(cgc.cPointer(), cauth, &count)
+	if err := cgc.close(); err != nil {
+		return nil, err
+	}
+	defer C.OSRDestroyCRSInfoList(clist)
+	ret := []CRSInfo{}
+	if count == 0 {
+		return ret, nil
+	}
+	infos := (*[1 << 24]*C.OSRCRSInfo)(unsafe.Pointer(clist))[:count:count]
+	for _, ci := range infos {
+		info := CRSInfo{
+			Authority:        C.GoString(ci.pszAuthName),
+			Code:             C.GoString(ci.pszCode),
+			Name:             C.GoString(ci.pszName),
+			Type:             CRSType(ci.eType),
+			Deprecated:       ci.bDeprecated != 0,
+			ProjectionMethod: C.GoString(ci.pszProjectionMethod),
+		}
+		if ci.bBboxValid != 0 {
+			info.AreaOfUse = &AreaOfUse{
+				Name:  C.GoString(ci.pszAreaName),
+				West:  float64(ci.dfWestLongitudeDeg),
+				South: float64(ci.dfSouthLatitudeDeg),
+				East:  float64(ci.dfEastLongitudeDeg),
+				North: float64(ci.dfNorthLatitudeDeg),
+			}
+		}
+		if lo.match(&info) {
+			ret = append(ret, info)
+		}
+	}
+	return ret, nil
+}
+
+// FindMatches returns the CRSs of the PROJ database matching the SpatialRef, sorted by
+// decreasing confidence. Contrary to AutoIdentifyEPSG, the SpatialRef is not modified.
+// A confidence of 100 denotes an exact match, lower values an equivalent CRS differing
+// e.g. by its name or axis order.
+func (sr *SpatialRef) FindMatches(opts ...FindMatchesOption) ([]SpatialRefMatch, error) {
+	fo := &findMatchesOpts{}
+	for _, o := range opts {
+		o.setFindMatchesOpt(fo)
+	}
+	var count C.int
+	var cconfidences *C.int
+	cgc := createCGOContext(nil, fo.errorHandler)
+	cmatches := C.godalFindMatches(cgc.cPointer(), sr.handle, &count, &cconfidences)
+	if err := cgc.close(); err != nil {
+		return nil, err
+	}
+	ret := []SpatialRefMatch{}
+	if cmatches == nil || count == 0 {
+		return ret, nil
+	}
+	defer C.godalFreeMatches(cmatches, cconfidences)
+	matches := (*[1 << 20]C.OGRSpatialReferenceH)(unsafe.Pointer(cmatches))[:count:count]
+	confidences := (*[1 << 20]C.int)(unsafe.Pointer(cconfidences))[:count:count]
+	for i, m := range matches {
+		match := SpatialRefMatch{
+			Authority:  C.GoString(C.OSRGetAuthorityName(m, nil)),
+			Code:       C.GoString(C.OSRGetAuthorityCode(m, nil)),
+			Name:       C.GoString(C.OSRGetName(m)),
+			Confidence: int(confidences[i]),
+		}
+		if fo.authority != "" && !strings.EqualFold(fo.authority, match.Authority) {
+			continue
+		}
+		ret = append(ret, match)
+	}
+	return ret, nil
+}
+
 // AutoIdentifyEPSG sets EPSG authority info if possible.
 func (sr *SpatialRef) AutoIdentifyEPSG() error {
 	ogrerr := C.OSRAutoIdentifyEPSG(sr.handle)
@@ -2522,7 +4378,7 @@ func (layer Layer) Type() GeometryType {
 
 // Bounds returns the layer's envelope in the order minx,miny,maxx,maxy
 func (layer Layer) Bounds(opts ...BoundsOption) ([4]float64, error) {
-	bo := boundsOpts{}
+	bo := boundsOpts{densifyPts: defaultDensifyPts}
 	for _, o := range opts {
 		o.setBoundsOpt(&bo)
 	}
@@ -2543,7 +4399,7 @@ func (layer Layer) Bounds(opts ...BoundsOption) ([4]float64, error) {
 	}
 	sr := layer.SpatialRef()
 	defer sr.Close()
-	bnds, err := reprojectBounds(bnds, sr, bo.sr)
+	bnds, err := reprojectBounds(bnds, sr, bo.sr, bo.densifyPts, bo.errorHandler)
 	if err != nil {
 		return [4]float64{}, err
 	}
@@ -2585,7 +4441,8 @@ func (ds *Dataset) Layers() []Layer {
 	}
 }
 
-// SpatialRef returns dataset projection.
+// SpatialRef returns the layer projection. For dynamic CRSs, the returned SpatialRef carries
+// the coordinate epoch if the driver stores it (see SpatialRef.CoordinateEpoch).
 func (layer Layer) SpatialRef() *SpatialRef {
 	hndl := C.OGR_L_GetSpatialRef(layer.handle())
 	return &SpatialRef{handle: hndl, isOwned: false}
@@ -2800,7 +4657,7 @@ func (g *Geometry) Valid() bool {
 
 // Bounds returns the geometry's envelope in the order minx,miny,maxx,maxy
 func (g *Geometry) Bounds(opts ...BoundsOption) ([4]float64, error) {
-	bo := boundsOpts{}
+	bo := boundsOpts{densifyPts: defaultDensifyPts}
 	for _, o := range opts {
 		o.setBoundsOpt(&bo)
 	}
@@ -2817,7 +4674,7 @@ func (g *Geometry) Bounds(opts ...BoundsOption) ([4]float64, error) {
 	}
 	sr := g.SpatialRef()
 	defer sr.Close()
-	ret, err := reprojectBounds(bnds, sr, bo.sr)
+	ret, err := reprojectBounds(bnds, sr, bo.sr, bo.densifyPts, bo.errorHandler)
 	if err != nil {
 		return bnds, err
 	}
@@ -2876,6 +4733,11 @@ func (f *Feature) SetGeometryColumnName(name string, opts ...SetGeometryColumnNa
 	return cgc.close()
 }
 
+// FID returns the feature identifier, or -1 if it has not been set
+func (f *Feature) FID() int64 {
+	return int64(C.OGR_F_GetFID(f.handle))
+}
+
 // SetFID set feature identifier
 func (f *Feature) SetFID(fid int64) {
 	// OGR error returned is always none, so we don't handle it
@@ -4175,6 +6037,333 @@ func GCPsToGeoTransform(GCPList []GCP, opts ...GCPsToGeoTransformOption) ([6]flo
 	return ret, nil
 }
 
+// Calc evaluates a band math expression (see Expression for the supported syntax) and
+// writes the result to a new single band dataset named dstDS.
+//
+// inputs maps the variable names used in the expression to the bands they refer to. The
+// input bands may come from different datasets, but must have the same size and, for the
+// datasets that are georeferenced, the same geotransform and spatial reference. The
+// georeferencing of the output is copied from the georeferenced input datasets.
+//
+// Pixels for which any of the referenced input bands contains its nodata value are set to
+// the output nodata value (see OutputNoData).
+//
+// The expression is evaluated window by window and written with the requested DriverName
+// (GTiff by default). Drivers that only support CreateCopy (e.g. COG, PNG or JPEG) can
+// also be used, in which case the whole result is first computed into an in-memory
+// dataset, and must therefore fit in memory, before being copied. If the VRT
+// driver is requested, no pixels are computed and Calc returns a VRT dataset containing a
+// derived band whose pixel function evaluates the expression on the fly. Such VRT datasets
+// require GDAL >= 3.4, can only be read by programs using godal, and their input bands must
+// belong to datasets that can be reopened by name (i.e. not in-memory datasets).
+func Calc(dstDS string, expression string, inputs map[string]Band, opts ...CalcOption) (*Dataset, error) {
+	co := calcOpts{
+		driver: GTiff,
+		dtype:  Float32,
+	}
+	for _, opt := range opts {
+		opt.setCalcOpt(&co)
+	}
+	expr, err := ParseExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(expr.vars) == 0 {
+		return nil, fmt.Errorf("expression %q does not reference any band", expression)
+	}
+	bands := make([]Band, len(expr.vars))
+	srcNoData := make([]*float64, len(expr.vars))
+	for i, v := range expr.vars {
+		band, ok := inputs[v]
+		if !ok {
+			return nil, fmt.Errorf("no input band for variable %s", v)
+		}
+		bands[i] = band
+		if nd, ok := band.NoData(); ok {
+			srcNoData[i] = &nd
+			if !co.hasNoData {
+				co.nodata = nd
+				co.hasNoData = true
+			}
+		}
+	}
+	st := bands[0].Structure()
+	for i := range bands[1:] {
+		bst := bands[i+1].Structure()
+		if bst.SizeX != st.SizeX || bst.SizeY != st.SizeY {
+			return nil, fmt.Errorf("band %s has size %dx%d, expected %dx%d",
+				expr.vars[i+1], bst.SizeX, bst.SizeY, st.SizeX, st.SizeY)
+		}
+	}
+	var gt *[6]float64
+	var srs *SpatialRef
+	for i, band := range bands {
+		bgt, bsrs := calcGeoref(band)
+		if bgt != nil {
+			if gt == nil {
+				gt = bgt
+			} else if *bgt != *gt {
+				return nil, fmt.Errorf("band %s has geotransform %v, expected %v", expr.vars[i], *bgt, *gt)
+			}
+		}
+		if bsrs != nil {
+			if srs == nil {
+				srs = bsrs
+			} else if !bsrs.IsSame(srs) {
+				return nil, fmt.Errorf("band %s has a different spatial reference", expr.vars[i])
+			}
+		}
+	}
+	if co.driver == VRT {
+		return calcVRT(dstDS, expr, bands, srcNoData, gt, srs, co)
+	}
+
+	drvname := string(co.driver)
+	if dm, ok := driverMappings[co.driver]; ok {
+		drvname = dm.rasterName
+	}
+	drv, ok := getDriver(drvname)
+	if !ok {
+		return nil, fmt.Errorf("failed to get driver %s", drvname)
+	}
+	if drv.CanCreate() {
+		ds, err := Create(co.driver, dstDS, 1, co.dtype, st.SizeX, st.SizeY,
+			CreationOption(co.creation...), ConfigOption(co.config...), ErrLogger(co.errorHandler))
+		if err != nil {
+			return nil, err
+		}
+		if err = calcInto(ds, expr, bands, srcNoData, gt, srs, co); err != nil {
+			_ = ds.Close()
+			return nil, err
+		}
+		return ds, nil
+	}
+
+	// drivers only supporting CreateCopy (e.g. COG, PNG or JPEG): the result is computed
+	// into an in-memory dataset which is then copied
+	mem, err := Create(Memory, "", 1, co.dtype, st.SizeX, st.SizeY,
+		ConfigOption(co.config...), ErrLogger(co.errorHandler))
+	if err != nil {
+		return nil, err
+	}
+	defer mem.Close()
+	if err = calcInto(mem, expr, bands, srcNoData, gt, srs, co); err != nil {
+		return nil, err
+	}
+	cname := C.CString(dstDS)
+	defer C.free(unsafe.Pointer(cname))
+	createOpts := sliceToCStringArray(co.creation)
+	defer createOpts.free()
+	cgc := createCGOContext(co.config, co.errorHandler)
+	hndl := C.godalCalcCreateCopy(cgc.cPointer(), drv.handle(), cname, mem.handle(), createOpts.cPointer())
+	if err := cgc.close(); err != nil {
+		if hndl != nil {
+			C.GDALClose(hndl)
+		}
+		return nil, err
+	}
+	return &Dataset{majorObject{C.GDALMajorObjectH(hndl)}}, nil
+}
+
+// calcGeoref returns the geotransform and spatial reference of the dataset of band, or nil
+// if it has none
+func calcGeoref(band Band) (*[6]float64, *SpatialRef) {
+	hds := C.GDALGetBandDataset(band.handle())
+	if hds == nil {
+		return nil, nil
+	}
+	var gt *[6]float64
+	var srs *SpatialRef
+	srcDS := Dataset{majorObject{C.GDALMajorObjectH(hds)}}
+	if sgt, err := srcDS.GeoTransform(); err == nil {
+		gt = &sgt
+	}
+	if sr := srcDS.SpatialRef(); sr.handle != nil {
+		srs = sr
+	}
+	return gt, srs
+}
+
+func calcInto(ds *Dataset, expr *Expression, bands []Band, srcNoData []*float64, gt *[6]float64, srs *SpatialRef, co calcOpts) error {
+	if gt != nil {
+		if err := ds.SetGeoTransform(*gt, ErrLogger(co.errorHandler)); err != nil {
+			return err
+		}
+	}
+	if srs != nil {
+		if err := ds.SetSpatialRef(srs, ErrLogger(co.errorHandler)); err != nil {
+			return err
+		}
+	}
+	dst := ds.Bands()[0]
+	if co.hasNoData {
+		if err := dst.SetNoData(co.nodata, ErrLogger(co.errorHandler)); err != nil {
+			return err
+		}
+	}
+	st := dst.Structure()
+	if co.blockSizeX > 0 && co.blockSizeY > 0 {
+		st.BlockSizeX, st.BlockSizeY = co.blockSizeX, co.blockSizeY
+	} else {
+		// follow the layout of the inputs rather than the one of the (possibly in-memory)
+		// output
+		bst := bands[0].Structure()
+		st.BlockSizeX, st.BlockSizeY = bst.BlockSizeX, bst.BlockSizeY
+	}
+	srcs := make([][]float64, len(bands))
+	for i := range srcs {
+		srcs[i] = make([]float64, st.BlockSizeX*st.BlockSizeY)
+	}
+	out := make([]float64, st.BlockSizeX*st.BlockSizeY)
+	ioOpts := []BandIOOption{ConfigOption(co.config...), ErrLogger(co.errorHandler)}
+	for bl, ok := st.FirstBlock(), true; ok; bl, ok = bl.Next() {
+		n := bl.W * bl.H
+		bsrcs := make([][]float64, len(srcs))
+		for i, band := range bands {
+			bsrcs[i] = srcs[i][:n]
+			if err := band.Read(bl.X0, bl.Y0, bsrcs[i], bl.W, bl.H, ioOpts...); err != nil {
+				return err
+			}
+		}
+		expr.evalBlock(bsrcs, srcNoData, co.nodata, out[:n])
+		if err := dst.Write(bl.X0, bl.Y0, out[:n], bl.W, bl.H, ioOpts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var calcPixelFuncMu sync.Mutex
+var calcPixelFuncRegistered bool
+
+func calcVRT(dstDS string, expr *Expression, bands []Band, srcNoData []*float64, gt *[6]float64, srs *SpatialRef, co calcOpts) (*Dataset, error) {
+	st := bands[0].Structure()
+	vrt := calcVRTDataset{
+		XSize: st.SizeX,
+		YSize: st.SizeY,
+		Band: calcVRTBand{
+			DataType:           co.dtype.String(),
+			Band:               1,
+			SubClass:           "VRTDerivedRasterBand",
+			PixelFunctionType:  "godal_calc",
+			SourceTransferType: Float64.String(),
+		},
+	}
+	if gt != nil {
+		sgt := make([]string, 6)
+		for i := range gt {
+			sgt[i] = strconv.FormatFloat(gt[i], 'g', -1, 64)
+		}
+		vrt.GeoTransform = strings.Join(sgt, ", ")
+	}
+	if srs != nil {
+		wkt, err := srs.WKT(ErrLogger(co.errorHandler))
+		if err != nil {
+			return nil, err
+		}
+		vrt.SRS = wkt
+	}
+	nds := make([]string, len(bands))
+	for i, band := range bands {
+		hds := C.GDALGetBandDataset(band.handle())
+		var name string
+		if hds != nil {
+			name = C.GoString(C.GDALGetDescription(C.GDALMajorObjectH(hds)))
+		}
+		if name == "" {
+			return nil, fmt.Errorf("band %s does not belong to a dataset that can be referenced by name", expr.vars[i])
+		}
+		vrt.Band.Sources = append(vrt.Band.Sources, calcVRTSource{
+			Filename: calcVRTSourceFilename{Name: name},
+			Band:     int(C.GDALGetBandNumber(band.handle())),
+		})
+		if srcNoData[i] != nil {
+			nds[i] = calcFormatFloat(*srcNoData[i])
+		}
+	}
+	vrt.Band.PixelFunctionArguments = calcVRTArguments{
+		Expression: expr.src,
+		Variables:  strings.Join(expr.vars, ","),
+		SrcNoData:  strings.Join(nds, ","),
+	}
+	if co.hasNoData {
+		vrt.Band.NoDataValue = calcFormatFloat(co.nodata)
+		vrt.Band.PixelFunctionArguments.NoData = vrt.Band.NoDataValue
+	}
+	xmlvrt, err := xml.Marshal(vrt)
+	if err != nil {
+		return nil, err
+	}
+
+	calcPixelFuncMu.Lock()
+	if !calcPixelFuncRegistered {
+		cgc := createCGOContext(co.config, co.errorHandler)
+		C.godalRegisterCalcPixelFunction(cgc.cPointer())
+		if err := cgc.close(); err != nil {
+			calcPixelFuncMu.Unlock()
+			return nil, err
+		}
+		calcPixelFuncRegistered = true
+	}
+	calcPixelFuncMu.Unlock()
+
+	ds, err := Open(string(xmlvrt), RasterOnly(), ConfigOption(co.config...), ErrLogger(co.errorHandler))
+	if err != nil || dstDS == "" {
+		return ds, err
+	}
+	drv, ok := RasterDriver(VRT)
+	if !ok {
+		_ = ds.Close()
+		return nil, fmt.Errorf("failed to get driver %s", VRT)
+	}
+	cname := C.CString(dstDS)
+	defer C.free(unsafe.Pointer(cname))
+	createOpts := sliceToCStringArray(co.creation)
+	defer createOpts.free()
+	cgc := createCGOContext(co.config, co.errorHandler)
+	hndl := C.godalCalcCreateCopy(cgc.cPointer(), drv.handle(), cname, ds.handle(), createOpts.cPointer())
+	_ = ds.Close()
+	if err := cgc.close(); err != nil {
+		return nil, err
+	}
+	return &Dataset{majorObject{C.GDALMajorObjectH(hndl)}}, nil
+}
+
+//export _gogdalCalcCallback
+func _gogdalCalcCallback(cexpr, cvars, csrcnd, cdstnd *C.char, nSources C.int, csrcs, cout *C.double, npix C.longlong, errorString **C.char) {
+	pf, err := getCalcPixelFunc(C.GoString(cexpr), C.GoString(cvars), C.GoString(csrcnd), C.GoString(cdstnd))
+	if err != nil {
+		*errorString = C.CString(err.Error())
+		return
+	}
+	const maxInt = int64(^uint(0) >> 1)
+	n, ns := int64(npix), int64(nSources)
+	if n < 0 || ns < 0 || (ns > 0 && n > maxInt/ns) {
+		*errorString = C.CString(fmt.Sprintf("invalid buffer of %d pixels for %d sources", n, ns))
+		return
+	}
+	all := float64Slice(csrcs, int(n*ns))
+	srcs := make([][]float64, ns)
+	for i := range srcs {
+		srcs[i] = all[int64(i)*n : int64(i+1)*n]
+	}
+	out := float64Slice(cout, int(n))
+	if err = pf.eval(srcs, out); err != nil {
+		*errorString = C.CString(err.Error())
+	}
+}
+
+// float64Slice returns a slice backed by the n C doubles starting at p. Contrary to
+// converting p to a pointer to a fixed size array, this works for any n.
+func float64Slice(p *C.double, n int) []float64 {
+	var ret []float64
+	sh := (*reflect.SliceHeader)(unsafe.Pointer(&ret))
+	sh.Data = uintptr(unsafe.Pointer(p))
+	sh.Len = n
+	sh.Cap = n
+	return ret
+}
+
 type cgoContext struct {
 	cctx *C.cctx
 	opts cStringArray
@@ -4219,3 +6408,12 @@ func (cgc cgoContext) close() error {
 	}
 	return nil
 }
+
+//export _gogdalProgressCallback
+func _gogdalProgressCallback(complete C.double, msg *C.char, progressIdx C.int) C.int {
+	fn := getProgress(int(progressIdx))
+	if fn == nil || fn(float64(complete), C.GoString(msg)) {
+		return 1
+	}
+	return 0
+}
@@ -27,6 +27,12 @@
 	#error "this code is only compatible with gdal version >= 3.0"
 #endif
 
+#if GDAL_VERSION_NUM < GDAL_COMPUTE_VERSION(3, 5, 0)
+	// 64 bit integer datatypes are not supported before GDAL 3.5, GDAL calls using them will fail
+	#define GDT_UInt64 12
+	#define GDT_Int64 13
+#endif
+
 #ifdef __cplusplus
 extern "C" {
 #endif
@@ -46,6 +52,8 @@ extern "C" {
 							GDALDataType dtype, char **creationOption);
 
 	void godalClose(cctx *ctx, GDALDatasetH ds);
+	char **godalGetOpenOptions(GDALDatasetH ds);
+	GDALDriverH godalIdentifyDriver(cctx *ctx, const char *name, unsigned int nIdentifyFlags, char **papszAllowedDrivers, char **papszSiblingFiles);
 	int godalRegisterDriver(const char *funcname);
 	void godalRegisterPlugins();
 	void godalRegisterPlugin(cctx *ctx, const char *name);
@@ -58,20 +66,73 @@ extern "C" {
 	GDALRasterBandH* godalBandOverviews(GDALRasterBandH bnd);
 
 	void godalSetRasterNoDataValue(cctx *ctx, GDALRasterBandH bnd, double nd);
+	long long godalGetRasterNoDataValueAsInt64(GDALRasterBandH bnd, int *ok);
+	unsigned long long godalGetRasterNoDataValueAsUInt64(GDALRasterBandH bnd, int *ok);
+	void godalSetRasterNoDataValueAsInt64(cctx *ctx, GDALRasterBandH bnd, long long nd);
+	void godalSetRasterNoDataValueAsUInt64(cctx *ctx, GDALRasterBandH bnd, unsigned long long nd);
 	void godalSetDatasetNoDataValue(cctx *ctx, GDALDatasetH bnd, double nd);
 	void godalDeleteRasterNoDataValue(cctx *ctx, GDALRasterBandH bnd);
 	void godalSetRasterScaleOffset(cctx *ctx, GDALRasterBandH bnd, double scale, double offset);
+	void godalRATReadColumn(cctx *ctx, GDALRasterAttributeTableH rat, int iCol, int nRows, int *ints, double *doubles, char **strings);
+	void godalSetRasterAttributeTable(cctx *ctx, GDALRasterBandH bnd, int tableType, int linearBinning, double row0Min, double binSize,
+									  int nCols, char **names, int *types, int *usages, int nRows, int *ints, double *doubles, char **strings);
+	void godalComputeRasterMinMax(cctx *ctx, GDALRasterBandH bnd, int bApproxOK, double *minmax);
+	void godalSetRasterCategoryNames(cctx *ctx, GDALRasterBandH bnd, char **names);
+	void godalSetRasterUnitType(cctx *ctx, GDALRasterBandH bnd, const char *unit);
 	void godalSetDatasetScaleOffset(cctx *ctx, GDALDatasetH bnd, double scale, double offset);
 	void godalSetRasterColorInterpretation(cctx *ctx, GDALRasterBandH bnd, GDALColorInterp ci);
 	GDALRasterBandH godalCreateMaskBand(cctx *ctx, GDALRasterBandH bnd, int flags);
 	GDALRasterBandH godalCreateDatasetMaskBand(cctx *ctx, GDALDatasetH ds, int flags);
-	OGRSpatialReferenceH godalCreateUserSpatialRef(cctx *ctx, char *userInput);
-	OGRSpatialReferenceH godalCreateWKTSpatialRef(cctx *ctx, char *wkt);
-	OGRSpatialReferenceH godalCreateProj4SpatialRef(cctx *ctx, char *proj);
-	OGRSpatialReferenceH godalCreateEPSGSpatialRef(cctx *ctx, int epsgCode);
+	OGRSpatialReferenceH godalCreateUserSpatialRef(cctx *ctx, char *userInput, int axisStrategy);
+	OGRSpatialReferenceH godalCreateWKTSpatialRef(cctx *ctx, char *wkt, int axisStrategy);
+	OGRSpatialReferenceH godalCreateProj4SpatialRef(cctx *ctx, char *proj, int axisStrategy);
+	OGRSpatialReferenceH godalCreateEPSGSpatialRef(cctx *ctx, int epsgCode, int axisStrategy);
+	OGRSpatialReferenceH godalCreateCompoundSpatialRef(cctx *ctx, char *name, OGRSpatialReferenceH horiz, OGRSpatialReferenceH vert, int axisStrategy);
+	int godalSpatialRefIs3D(OGRSpatialReferenceH sr);
+	void godalPromoteTo3D(cctx *ctx, OGRSpatialReferenceH sr, char *name);
+	void godalDemoteTo2D(cctx *ctx, OGRSpatialReferenceH sr, char *name);
+	int godalLongitudeFirst(OGRSpatialReferenceH sr);
+	double godalGetCoordinateEpoch(OGRSpatialReferenceH sr);
+	void godalSetCoordinateEpoch(cctx *ctx, OGRSpatialReferenceH sr, double epoch);
+	char *godalExportToWKTEx(cctx *ctx, OGRSpatialReferenceH sr, char **options);
+	char *godalExportToPROJJSON(cctx *ctx, OGRSpatialReferenceH sr, char **options);
+	char *godalExportToProj4(cctx *ctx, OGRSpatialReferenceH sr);
+	OSRCRSInfo **godalGetCRSInfoList(cctx *ctx, char *authName, int *count);
+	OGRSpatialReferenceH *godalFindMatches(cctx *ctx, OGRSpatialReferenceH sr, int *count, int **confidences);
+	void godalFreeMatches(OGRSpatialReferenceH *matches, int *confidences);
+	int godalIsSameSpatialRef(OGRSpatialReferenceH sr, OGRSpatialReferenceH other, char **options);
+	OGRSpatialReferenceH godalCloneGeogCS(cctx *ctx, OGRSpatialReferenceH sr);
+	void godalStripVertical(cctx *ctx, OGRSpatialReferenceH sr);
 	void godalValidateSpatialRef(cctx *ctx, OGRSpatialReferenceH sr);
 	char* godalExportToWKT(cctx *ctx, OGRSpatialReferenceH sr);
 	OGRCoordinateTransformationH godalNewCoordinateTransformation(cctx *ctx,  OGRSpatialReferenceH src, OGRSpatialReferenceH dst);
+	int godalTransformBounds(cctx *ctx, OGRCoordinateTransformationH trn, double *bnds, int densifyPts);
+	typedef struct {
+		int hasAreaOfInterest;
+		double west, south, east, north;
+		double desiredAccuracy;
+		int ballpark;
+		char *operation;
+		int reverse;
+	} godalCTOptions;
+	typedef struct {
+		char *name;
+		char *proj;
+		double accuracy;
+		int instantiable;
+		int hasAreaOfUse;
+		double west, south, east, north;
+		char *areaName;
+	} godalCoordOp;
+	OGRCoordinateTransformationH godalNewCoordinateTransformationEx(cctx *ctx, OGRSpatialReferenceH src, OGRSpatialReferenceH dst, godalCTOptions *opts);
+	godalCoordOp *godalCoordinateOperations(cctx *ctx, OGRSpatialReferenceH src, OGRSpatialReferenceH dst, godalCTOptions *opts, int discardMissingGrids, int *count);
+	void godalFreeCoordinateOperations(godalCoordOp *ops, int count);
+	OGRCoordinateTransformationH godalCloneCoordinateTransformation(cctx *ctx, OGRCoordinateTransformationH trn);
+	int godalTransformInterleaved(OGRCoordinateTransformationH trn, int n, int dim, double *coords, unsigned char *success);
+	OGRSpatialReferenceH godalTransformSourceCS(OGRCoordinateTransformationH trn);
+	OGRSpatialReferenceH godalTransformTargetCS(OGRCoordinateTransformationH trn);
+	void *godalCreateGenImgProjTransformer(cctx *ctx, GDALDatasetH src, GDALDatasetH dst, char **options);
+	void godalGenImgProjTransform(cctx *ctx, void *tr, int dstToSrc, int n, double *x, double *y, double *z, int *success);
 	void godalDatasetSetSpatialRef(cctx *ctx, GDALDatasetH ds, OGRSpatialReferenceH sr);
 	void godalSetGeoTransform(cctx *ctx, GDALDatasetH ds, double *gt);
 	void godalGetGeoTransform(cctx *ctx, GDALDatasetH ds, double *gt);
@@ -93,6 +154,10 @@ extern "C" {
 		int nPixelSpace, int nLineSpace, int nBandSpace, GDALRIOResampleAlg alg);
 	void godalBandRasterIO(cctx *ctx, GDALRasterBandH bnd, GDALRWFlag rw, int nDSXOff, int nDSYOff, int nDSXSize, int nDSYSize, void *pBuffer,
 		int nBXSize, int nBYSize, GDALDataType eBDataType, int nPixelSpace, int nLineSpace, GDALRIOResampleAlg alg);
+	void godalDatasetAdviseRead(cctx *ctx, GDALDatasetH ds, int nDSXOff, int nDSYOff, int nDSXSize, int nDSYSize,
+		int nBXSize, int nBYSize, int nBandCount, int *panBandCount);
+	void godalBandAdviseRead(cctx *ctx, GDALRasterBandH bnd, int nDSXOff, int nDSYOff, int nDSXSize, int nDSYSize,
+		int nBXSize, int nBYSize);
 	void godalFillRaster(cctx *ctx, GDALRasterBandH bnd, double real, double imag);
 	void godalPolygonize(cctx *ctx, GDALRasterBandH in, GDALRasterBandH mask, OGRLayerH layer, int fieldIndex, char **opts);
 	void godalFillNoData(cctx *ctx, GDALRasterBandH in, GDALRasterBandH mask, int maxDistance, int iterations, char **opts);
@@ -180,6 +245,14 @@ extern "C" {
 	void godalSetGCPs2(cctx *ctx, GDALDatasetH hSrcDS, int numGCPs, goGCPList GCPList, OGRSpatialReferenceH hSRS);
 	GDAL_GCP *goGCPListToGDALGCP(goGCPList GCPList, int numGCPs);
 	void godalGCPListToGeoTransform(cctx *ctx, goGCPList GCPList, int numGCPs, double *gt);
+
+	void godalDeleteDataset(cctx *ctx, GDALDriverH drv, const char *name);
+	void godalRenameDataset(cctx *ctx, GDALDriverH drv, const char *newName, const char *oldName);
+	void godalCopyDatasetFiles(cctx *ctx, GDALDriverH drv, const char *newName, const char *oldName);
+	void godalValidateCreationOptions(cctx *ctx, GDALDriverH drv, char **options);
+	GDALDatasetH godalCreateCopy(cctx *ctx, GDALDriverH drv, const char *name, GDALDatasetH src, int strict, char **options, int progressIdx);
+	GDALDatasetH godalCalcCreateCopy(cctx *ctx, GDALDriverH drv, const char *name, GDALDatasetH src, char **options);
+	void godalRegisterCalcPixelFunction(cctx *ctx);
 #ifdef __cplusplus
 }
 #endif
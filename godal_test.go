@@ -17,6 +17,7 @@ package godal
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -26,11 +27,13 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"testing"
 	"time"
 
 	"cloud.google.com/go/storage"
+	"github.com/airbusgeo/godal/geom"
 	"github.com/airbusgeo/osio"
 	"github.com/airbusgeo/osio/gcs"
 	"github.com/stretchr/testify/assert"
@@ -161,6 +164,101 @@ func TestColorTable(t *testing.T) {
 	assert.Len(t, ct3.Entries, 0)
 }
 
+func TestAttributeTable(t *testing.T) {
+	tmpname := tempfile()
+	defer os.Remove(tmpname)
+	defer os.Remove(tmpname + ".aux.xml")
+	ds, _ := Create(GTiff, tmpname, 1, Byte, 10, 10)
+	bnd := ds.Bands()[0]
+	rat, err := bnd.AttributeTable()
+	require.NoError(t, err)
+	assert.Empty(t, rat.Columns)
+
+	at := AttributeTable{
+		TableType: ThematicTable,
+		Columns: []RATColumn{
+			{Name: "Value", Type: RATInteger, Usage: RATMinMax, Integers: []int{1, 3}},
+			{Name: "Class", Type: RATString, Usage: RATName, Strings: []string{"water", "forest"}},
+			{Name: "Ratio", Type: RATReal, Usage: RATGeneric, Reals: []float64{0.5, 0.25}},
+			{Name: "R", Type: RATInteger, Usage: RATRed, Integers: []int{0, 0}},
+			{Name: "G", Type: RATInteger, Usage: RATGreen, Integers: []int{0, 255}},
+			{Name: "B", Type: RATInteger, Usage: RATBlue, Integers: []int{255, 0}},
+		},
+	}
+	err = bnd.SetAttributeTable(at)
+	require.NoError(t, err)
+	_ = ds.Close()
+
+	ds, _ = Open(tmpname)
+	bnd = ds.Bands()[0]
+	rat, err = bnd.AttributeTable()
+	require.NoError(t, err)
+	assert.Equal(t, 2, rat.RowCount())
+	assert.Equal(t, at.Columns, rat.Columns)
+	assert.Equal(t, 1, rat.Column(RATName))
+	assert.Equal(t, -1, rat.Column(RATAlpha))
+	assert.Equal(t, "0.25", rat.Columns[2].StringValue(1))
+	assert.Equal(t, 3.0, rat.Columns[0].FloatValue(1))
+
+	names, err := rat.CategoryNames()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "water", "", "forest"}, names)
+	ct, err := rat.ColorTable()
+	require.NoError(t, err)
+	assert.Equal(t, [][4]int16{{}, {0, 0, 255, 255}, {}, {0, 255, 0, 255}}, ct.Entries)
+	_ = ds.Close()
+
+	mds, _ := Create(Memory, "", 1, Byte, 10, 10)
+	defer mds.Close()
+	mbnd := mds.Bands()[0]
+	binned := AttributeTable{
+		TableType:     AthematicTable,
+		LinearBinning: true,
+		Row0Min:       10,
+		BinSize:       2,
+		Columns: []RATColumn{
+			{Name: "Name", Type: RATString, Usage: RATName, Strings: []string{"a", "b"}},
+		},
+	}
+	ehc := eh()
+	err = mbnd.SetAttributeTable(binned, ErrLogger(ehc.ErrorHandler))
+	require.NoError(t, err)
+	rat, err = mbnd.AttributeTable(ErrLogger(ehc.ErrorHandler))
+	require.NoError(t, err)
+	assert.Equal(t, binned, rat)
+	names, _ = rat.CategoryNames()
+	assert.Equal(t, []string{"", "", "", "", "", "", "", "", "", "", "a", "a", "b", "b"}, names)
+	_, err = rat.ColorTable()
+	assert.Error(t, err)
+
+	err = mbnd.SetAttributeTable(AttributeTable{})
+	assert.NoError(t, err)
+	rat, _ = mbnd.AttributeTable()
+	assert.Empty(t, rat.Columns)
+
+	err = mbnd.SetAttributeTable(AttributeTable{Columns: []RATColumn{
+		{Name: "a", Type: RATInteger, Integers: []int{1, 2}},
+		{Name: "b", Type: RATReal, Reals: []float64{1}},
+	}})
+	assert.Error(t, err)
+
+	cat := AttributeTableFromCategoryNames([]string{"nodata", "", "urban"})
+	assert.Equal(t, []int{0, 2}, cat.Columns[0].Integers)
+	names, _ = cat.CategoryNames()
+	assert.Equal(t, []string{"nodata", "", "urban"}, names)
+	_, err = cat.ColorTable()
+	assert.Error(t, err)
+
+	ctat, err := AttributeTableFromColorTable(ColorTable{PaletteInterp: RGBPalette, Entries: [][4]int16{{1, 2, 3, 4}, {5, 6, 7, 8}}})
+	require.NoError(t, err)
+	ct, _ = ctat.ColorTable()
+	assert.Equal(t, [][4]int16{{1, 2, 3, 4}, {5, 6, 7, 8}}, ct.Entries)
+	_, err = AttributeTableFromColorTable(ColorTable{PaletteInterp: GrayscalePalette})
+	assert.Error(t, err)
+	_, err = ctat.CategoryNames()
+	assert.Error(t, err)
+}
+
 func TestCreate(t *testing.T) {
 	tmpname := tempfile()
 	defer os.Remove(tmpname)
@@ -282,6 +380,58 @@ func TestRegisterDrivers(t *testing.T) {
 	RegisterPlugins()
 }
 
+func TestDriverCapabilities(t *testing.T) {
+	drivers := RegisteredDrivers()
+	names := map[string]Driver{}
+	for _, drv := range drivers {
+		names[drv.ShortName()] = drv
+	}
+	gtiff, ok := names["GTiff"]
+	require.True(t, ok)
+	assert.True(t, gtiff.IsRaster())
+	assert.False(t, gtiff.IsVector())
+	assert.False(t, gtiff.IsMultiDimensional())
+	assert.True(t, gtiff.CanCreate())
+	assert.True(t, gtiff.CanCreateCopy())
+	assert.True(t, gtiff.SupportsVirtualIO())
+	assert.Contains(t, gtiff.Extensions(), "tif")
+	assert.Contains(t, gtiff.DataTypes(), Byte)
+	assert.Contains(t, gtiff.DataTypes(), CFloat64)
+
+	copts, err := gtiff.CreationOptions()
+	require.NoError(t, err)
+	found := false
+	for _, o := range copts {
+		if o.Name == "COMPRESS" {
+			found = true
+			assert.Equal(t, "string-select", o.Type)
+			vals := []string{}
+			for _, v := range o.Values {
+				vals = append(vals, v.Value)
+			}
+			assert.Contains(t, vals, "LZW")
+		}
+	}
+	assert.True(t, found)
+	oopts, err := gtiff.OpenOptions()
+	require.NoError(t, err)
+	assert.NotEmpty(t, oopts)
+
+	geojson, _ := VectorDriver(GeoJSON)
+	assert.True(t, geojson.IsVector())
+	assert.False(t, geojson.IsRaster())
+	assert.Empty(t, geojson.DataTypes())
+
+	err = gtiff.ValidateCreationOptions(CreationOption("COMPRESS=LZW", "TILED=YES"))
+	assert.NoError(t, err)
+	err = gtiff.ValidateCreationOptions(CreationOption("COMPRESS=BOGUS"))
+	assert.Error(t, err)
+	ehc := eh()
+	err = gtiff.ValidateCreationOptions(CreationOption("BOGUS=YES"), ErrLogger(ehc.ErrorHandler))
+	assert.Error(t, err)
+	assert.NotEqual(t, 0, ehc.errs)
+}
+
 func TestVectorCreate(t *testing.T) {
 	tf := tempfile()
 	defer os.Remove(tf)
@@ -494,6 +644,103 @@ func TestSize(t *testing.T) {
 
 }
 
+func TestGeoTransformHelpers(t *testing.T) {
+	gt := [6]float64{10, 0.5, 0, 20, 0, -0.5}
+	x, y := ApplyGeoTransform(gt, 2, 4)
+	assert.Equal(t, 11.0, x)
+	assert.Equal(t, 18.0, y)
+	inv, err := InvGeoTransform(gt)
+	assert.NoError(t, err)
+	px, py := ApplyGeoTransform(inv, x, y)
+	assert.InDelta(t, 2.0, px, 1e-12)
+	assert.InDelta(t, 4.0, py, 1e-12)
+	rot := [6]float64{100, 0.8, 0.6, 200, 0.6, -0.8}
+	inv, err = InvGeoTransform(rot)
+	assert.NoError(t, err)
+	x, y = ApplyGeoTransform(rot, 3, 7)
+	px, py = ApplyGeoTransform(inv, x, y)
+	assert.InDelta(t, 3.0, px, 1e-9)
+	assert.InDelta(t, 7.0, py, 1e-9)
+	_, err = InvGeoTransform([6]float64{0, 1, 2, 0, 2, 4})
+	assert.Error(t, err)
+
+	ds, _ := Create(Memory, "", 1, Byte, 100, 100)
+	defer ds.Close()
+	_, _, err = ds.PixelToGeo(0, 0)
+	assert.Error(t, err) //no geotransform
+	_ = ds.SetGeoTransform(gt)
+
+	x, y, err = ds.PixelToGeo(0.5, 0.5)
+	assert.NoError(t, err)
+	assert.Equal(t, 10.25, x)
+	assert.Equal(t, 19.75, y)
+	px, py, err = ds.GeoToPixel(10.25, 19.75)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, px)
+	assert.Equal(t, 0.5, py)
+	xs, ys := []float64{0, 100}, []float64{0, 100}
+	assert.NoError(t, ds.PixelToGeoPoints(xs, ys))
+	assert.Equal(t, []float64{10, 60}, xs)
+	assert.Equal(t, []float64{20, -30}, ys)
+	assert.NoError(t, ds.GeoToPixelPoints(xs, ys))
+	assert.Equal(t, []float64{0, 100}, xs)
+	assert.Equal(t, []float64{0, 100}, ys)
+	assert.Error(t, ds.GeoToPixelPoints(xs, ys[0:1]))
+
+	sr4326, _ := NewSpatialRefFromEPSG(4326)
+	defer sr4326.Close()
+	sr3857, _ := NewSpatialRefFromEPSG(3857)
+	defer sr3857.Close()
+	_, _, err = ds.PixelToGeo(0, 0, sr3857)
+	assert.Error(t, err) //no srs on dataset
+	_ = ds.SetSpatialRef(sr4326)
+	x, y, err = ds.PixelToGeo(0, 0, sr3857)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1113194.9, x, 0.1)
+	px, py, err = ds.GeoToPixel(x, y, sr3857, ErrLogger(eh().ErrorHandler))
+	assert.NoError(t, err)
+	assert.InDelta(t, 0, px, 1e-6)
+	assert.InDelta(t, 0, py, 1e-6)
+
+	bnds, err := ds.Bounds()
+	assert.NoError(t, err)
+	assert.Equal(t, [4]float64{10, -30, 60, 20}, bnds)
+	win, err := ds.WindowFromBounds([4]float64{11.1, 10.1, 12.9, 11.9})
+	assert.NoError(t, err)
+	assert.Equal(t, Block{X0: 2, Y0: 16, W: 4, H: 4}, win)
+	win, err = ds.WindowFromBounds([4]float64{11.1, 10.1, 12.9, 11.9}, Snap(SnapIn))
+	assert.NoError(t, err)
+	assert.Equal(t, Block{X0: 3, Y0: 17, W: 2, H: 2}, win)
+	win, err = ds.WindowFromBounds([4]float64{11.1, 10.1, 12.9, 11.9}, Snap(SnapNearest))
+	assert.NoError(t, err)
+	assert.Equal(t, Block{X0: 2, Y0: 16, W: 4, H: 4}, win)
+	win, err = ds.WindowFromBounds([4]float64{0, 0, 15, 100})
+	assert.NoError(t, err)
+	assert.Equal(t, Block{X0: 0, Y0: 0, W: 10, H: 40}, win)
+	_, err = ds.WindowFromBounds([4]float64{100, 100, 110, 110})
+	assert.Error(t, err)
+	_, err = ds.WindowFromBounds([4]float64{11.1, 10.1, 11.2, 10.2}, Snap(SnapIn))
+	assert.Error(t, err)
+	_, err = ds.WindowFromBounds(bnds, Snap(SnapMode(42)))
+	assert.Error(t, err)
+	b3857, _ := ds.WindowBounds(win, sr3857)
+	win, err = ds.WindowFromBounds(b3857, sr3857, ErrLogger(eh().ErrorHandler))
+	assert.NoError(t, err)
+	assert.Equal(t, Block{X0: 0, Y0: 0, W: 10, H: 40}, win)
+
+	wb, err := ds.WindowBounds(Block{X0: 2, Y0: 16, W: 4, H: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, [4]float64{11, 10, 13, 12}, wb)
+
+	_ = ds.SetGeoTransform([6]float64{0, 1, 1, 0, 1, -1})
+	bnds, err = ds.Bounds()
+	assert.NoError(t, err)
+	assert.Equal(t, [4]float64{0, -100, 200, 100}, bnds)
+	_ = ds.SetGeoTransform([6]float64{0, 1, 2, 0, 2, 4})
+	_, _, err = ds.GeoToPixel(0, 0)
+	assert.Error(t, err)
+}
+
 func TestBands(t *testing.T) {
 	ds, err := Open("testdata/test.tif")
 	require.NoError(t, err)
@@ -567,6 +814,169 @@ func TestSetScale(t *testing.T) {
 	assert.Equal(t, 101.0, st.Offset)
 }
 
+func TestUnit(t *testing.T) {
+	ds, _ := Create(Memory, "", 1, Byte, 2, 2)
+	defer ds.Close()
+	bnd := ds.Bands()[0]
+	assert.Equal(t, "", bnd.Unit())
+	err := bnd.SetUnit("m")
+	assert.NoError(t, err)
+	ehc := eh()
+	err = bnd.SetUnit("ft", ErrLogger(ehc.ErrorHandler))
+	assert.NoError(t, err)
+	assert.Equal(t, "ft", bnd.Unit())
+}
+
+func TestScaledIO(t *testing.T) {
+	ds, _ := Create(Memory, "", 2, Int16, 2, 2)
+	defer ds.Close()
+	bnds := ds.Bands()
+	_ = ds.Write(0, 0, []int16{1, 10, 2, 20, 3, 30, 4, -9999}, 2, 2)
+	_ = bnds[0].SetScaleOffset(0.5, 100)
+	_ = bnds[1].SetScaleOffset(2, 0)
+	_ = bnds[1].SetNoData(-9999)
+
+	buf := make([]float64, 4)
+	err := bnds[0].Read(0, 0, buf, 2, 2, ApplyScaleOffset())
+	require.NoError(t, err)
+	assert.Equal(t, []float64{100.5, 101, 101.5, 102}, buf)
+	err = bnds[1].Read(0, 0, buf, 2, 2, ApplyScaleOffset())
+	require.NoError(t, err)
+	assert.Equal(t, []float64{20, 40, 60, -9999}, buf)
+
+	fbuf := make([]float32, 8)
+	err = ds.Read(0, 0, fbuf, 2, 2, ApplyScaleOffset())
+	require.NoError(t, err)
+	assert.Equal(t, []float32{100.5, 20, 101, 40, 101.5, 60, 102, -9999}, fbuf)
+	err = ds.Read(0, 0, fbuf, 2, 2, ApplyScaleOffset(), BandInterleaved())
+	require.NoError(t, err)
+	assert.Equal(t, []float32{100.5, 101, 101.5, 102, 20, 40, 60, -9999}, fbuf)
+
+	err = bnds[0].Read(0, 0, make([]int16, 4), 2, 2, ApplyScaleOffset())
+	assert.Error(t, err)
+	err = ds.Read(0, 0, make([]int16, 8), 2, 2, ApplyScaleOffset())
+	assert.Error(t, err)
+	err = bnds[0].Write(0, 0, buf, 2, 2, ApplyScaleOffset())
+	assert.Error(t, err)
+	err = ds.Write(0, 0, fbuf, 2, 2, ApplyScaleOffset())
+	assert.Error(t, err)
+}
+
+func TestComplexValues(t *testing.T) {
+	ds, _ := Create(Memory, "", 1, CInt16, 2, 1)
+	defer ds.Close()
+	bnd := ds.Bands()[0]
+	_ = bnd.Write(0, 0, []complex64{complex(3, 4), complex(0, -2)}, 2, 1)
+
+	buf := make([]float64, 2)
+	for _, tc := range []struct {
+		part ComplexPart
+		exp  []float64
+	}{
+		{RealPart, []float64{3, 0}},
+		{ImaginaryPart, []float64{4, -2}},
+		{Amplitude, []float64{5, 2}},
+		{Phase, []float64{math.Atan2(4, 3), -math.Pi / 2}},
+		{Intensity, []float64{25, 4}},
+	} {
+		err := bnd.Read(0, 0, buf, 2, 1, ComplexValues(tc.part))
+		require.NoError(t, err)
+		assert.InDeltaSlice(t, tc.exp, buf, 1e-9, tc.part.String())
+	}
+
+	_ = bnd.SetScaleOffset(2, 1)
+	err := bnd.Read(0, 0, buf, 2, 1, ComplexValues(RealPart), ApplyScaleOffset())
+	require.NoError(t, err)
+	assert.Equal(t, []float64{7, 1}, buf)
+	err = bnd.Read(0, 0, buf, 2, 1, ComplexValues(ImaginaryPart), ApplyScaleOffset())
+	require.NoError(t, err)
+	assert.Equal(t, []float64{8, -4}, buf)
+
+	_ = bnd.SetScaleOffset(2, 0)
+	fbuf := make([]float32, 4)
+	err = bnd.Read(0, 0, fbuf, 2, 1, ComplexValues(Amplitude), ApplyScaleOffset(), PixelStride(2))
+	require.NoError(t, err)
+	assert.Equal(t, []float32{10, 0, 4, 0}, fbuf)
+
+	_ = bnd.SetNoData(0)
+	mask := make([]byte, 2)
+	err = bnd.Read(0, 0, buf, 2, 1, ComplexValues(Intensity), ApplyScaleOffset(), ValidityMask(mask))
+	require.NoError(t, err)
+	assert.Equal(t, []float64{100, 0}, buf)
+
+	err = bnd.Read(0, 0, make([]complex64, 2), 2, 1, ComplexValues(Amplitude))
+	assert.Error(t, err)
+	err = bnd.Write(0, 0, buf, 2, 1, ComplexValues(Amplitude))
+	assert.Error(t, err)
+	assert.Equal(t, "ComplexPart(12)", ComplexPart(12).String())
+}
+
+func TestBandMinMax(t *testing.T) {
+	ds, _ := Create(Memory, "", 1, Int16, 4, 1)
+	defer ds.Close()
+	bnd := ds.Bands()[0]
+	_ = bnd.Write(0, 0, []int16{-5, 3, 12, 100}, 4, 1)
+	_ = bnd.SetNoData(100)
+
+	_, ok := bnd.Minimum()
+	assert.False(t, ok)
+	_, ok = bnd.Maximum()
+	assert.False(t, ok)
+
+	min, max, err := bnd.ComputeMinMax()
+	require.NoError(t, err)
+	assert.Equal(t, -5.0, min)
+	assert.Equal(t, 12.0, max)
+	ehc := eh()
+	min, max, err = bnd.ComputeMinMax(Approximate(), ErrLogger(ehc.ErrorHandler))
+	require.NoError(t, err)
+	assert.Equal(t, -5.0, min)
+	assert.Equal(t, 12.0, max)
+
+	_ = bnd.SetStatistics(-5, 12, 3, 1)
+	min, ok = bnd.Minimum()
+	assert.True(t, ok)
+	assert.Equal(t, -5.0, min)
+	max, ok = bnd.Maximum()
+	assert.True(t, ok)
+	assert.Equal(t, 12.0, max)
+
+	_ = bnd.Fill(100, 0)
+	_, _, err = bnd.ComputeMinMax()
+	assert.Error(t, err)
+
+	scale, ok := bnd.Scale()
+	assert.Equal(t, 1.0, scale)
+	offset, _ := bnd.Offset()
+	assert.Equal(t, 0.0, offset)
+	_ = bnd.SetScaleOffset(2, 3)
+	scale, ok = bnd.Scale()
+	assert.True(t, ok)
+	assert.Equal(t, 2.0, scale)
+	offset, ok = bnd.Offset()
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, offset)
+}
+
+func TestCategoryNames(t *testing.T) {
+	ds, _ := Create(Memory, "", 1, Byte, 4, 1)
+	defer ds.Close()
+	bnd := ds.Bands()[0]
+	assert.Nil(t, bnd.CategoryNames())
+	err := bnd.SetCategoryNames([]string{"nodata", "water", "forest"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nodata", "water", "forest"}, bnd.CategoryNames())
+
+	ehc := eh()
+	err = bnd.SetCategoryNames(nil, ErrLogger(ehc.ErrorHandler))
+	require.NoError(t, err)
+	assert.Nil(t, bnd.CategoryNames())
+
+	err = bnd.SetDescription("landcover")
+	assert.NoError(t, err)
+	assert.Equal(t, "landcover", bnd.Description())
+}
+
 func TestStructure(t *testing.T) {
 	tmpname := tempfile()
 	defer os.Remove(tmpname)
@@ -1209,6 +1619,66 @@ func TestBandMask(t *testing.T) {
 	}
 }
 
+func TestValidityMask(t *testing.T) {
+	ds, _ := Create(Memory, "", 2, Float32, 4, 2)
+	defer ds.Close()
+	bnds := ds.Bands()
+	_ = bnds[0].SetNoData(-1)
+	_ = ds.Write(0, 0, []float32{
+		-1, 1, 2, 3, 4, 5, 6, 7,
+		0, 1, -1, 3, 4, 5, float32(math.NaN()), 7,
+	}, 4, 2, BandSpacing(32), PixelSpacing(4), LineSpacing(16))
+	assert.Equal(t, MaskNoData, bnds[0].MaskFlags())
+	assert.Equal(t, MaskAllValid, bnds[1].MaskFlags())
+
+	buf := make([]float32, 8)
+	mask := make([]byte, 8)
+	err := bnds[0].Read(0, 0, buf, 4, 2, ValidityMask(mask))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 255, 255, 255, 255, 255, 255, 255}, mask)
+
+	err = bnds[1].Read(0, 0, buf, 4, 2, ValidityMask(mask))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{255, 255, 255, 255, 255, 255, 0, 255}, mask)
+
+	buf = make([]float32, 16)
+	err = ds.Read(0, 0, buf, 4, 2, ValidityMask(mask))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 255, 255, 255, 255, 255, 0, 255}, mask)
+
+	mask = make([]byte, 2)
+	err = ds.Read(0, 0, buf, 2, 1, ValidityMask(mask), Bands(1), Window(2, 1))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 255}, mask)
+
+	err = ds.Read(0, 0, buf, 4, 2, ValidityMask(mask))
+	assert.Error(t, err)
+	//invalid masks and spacings must be rejected before reading any pixel
+	for i := range buf {
+		buf[i] = 42
+	}
+	err = bnds[0].Read(0, 0, buf, 4, 2, ValidityMask(mask))
+	assert.Error(t, err)
+	err = bnds[0].Read(0, 0, buf, 2, 1, ValidityMask(mask), PixelSpacing(6))
+	assert.Error(t, err)
+	err = ds.Read(0, 0, buf, 2, 1, ValidityMask(mask), Bands(1), LineSpacing(10))
+	assert.Error(t, err)
+	assert.Equal(t, float32(42), buf[0])
+	err = bnds[0].Write(0, 0, buf, 4, 2, ValidityMask(mask))
+	assert.Error(t, err)
+	err = ds.Write(0, 0, buf, 4, 2, ValidityMask(mask))
+	assert.Error(t, err)
+
+	ds2, _ := Create(Memory, "", 2, Byte, 2, 1)
+	defer ds2.Close()
+	_, _ = ds2.CreateMaskBand(MaskPerDataset)
+	_ = ds2.Bands()[0].MaskBand().Write(0, 0, []byte{0, 128}, 2, 1)
+	mask = make([]byte, 2)
+	err = ds2.Read(0, 0, make([]byte, 4), 2, 1, ValidityMask(mask))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 128}, mask)
+}
+
 func TestSetNoData(t *testing.T) {
 	_ = RegisterRaster("HFA")
 	ds, _ := Open("testdata/test.img")
@@ -1226,6 +1696,114 @@ func TestSetNoData(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestNoData64(t *testing.T) {
+	ds, _ := Create(Memory, "", 1, Float64, 2, 2)
+	defer ds.Close()
+	bnd := ds.Bands()[0]
+	_, ok := bnd.NoDataInt64()
+	assert.False(t, ok)
+	err := bnd.SetNoDataInt64(-9999)
+	require.NoError(t, err)
+	nd, ok := bnd.NoDataInt64()
+	assert.True(t, ok)
+	assert.Equal(t, int64(-9999), nd)
+	_, ok = bnd.NoDataUInt64()
+	assert.False(t, ok)
+	err = bnd.SetNoDataUInt64(1 << 40)
+	require.NoError(t, err)
+	und, ok := bnd.NoDataUInt64()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1<<40), und)
+	ehc := eh()
+	err = bnd.SetNoDataInt64(1<<60+1, ErrLogger(ehc.ErrorHandler))
+	assert.Error(t, err)
+	err = bnd.SetNoDataUInt64(1<<63 + 1)
+	assert.Error(t, err)
+	_ = bnd.SetNoData(0.5)
+	_, ok = bnd.NoDataInt64()
+	assert.False(t, ok)
+
+	if Version().Major() < 3 || (Version().Major() == 3 && Version().Minor() < 5) {
+		return
+	}
+	assert.Equal(t, 8, Int64.Size())
+	ids, _ := Create(Memory, "", 1, Int64, 2, 2)
+	defer ids.Close()
+	ibnd := ids.Bands()[0]
+	err = ibnd.SetNoDataInt64(-1<<62 - 1)
+	require.NoError(t, err)
+	nd, ok = ibnd.NoDataInt64()
+	assert.True(t, ok)
+	assert.Equal(t, int64(-1<<62-1), nd)
+	_, ok = ibnd.NoDataUInt64()
+	assert.False(t, ok)
+	err = ibnd.SetNoDataUInt64(1<<63 + 1)
+	assert.Error(t, err)
+	buf := []int64{1<<62 + 1, 2, 3, 4}
+	err = ibnd.Write(0, 0, buf, 2, 2)
+	require.NoError(t, err)
+	rbuf := make([]int64, 4)
+	_ = ibnd.Read(0, 0, rbuf, 2, 2)
+	assert.Equal(t, buf, rbuf)
+
+	uds, _ := Create(Memory, "", 1, UInt64, 2, 2)
+	defer uds.Close()
+	ubnd := uds.Bands()[0]
+	err = ubnd.SetNoDataUInt64(1<<63 + 1)
+	require.NoError(t, err)
+	und, ok = ubnd.NoDataUInt64()
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1<<63+1), und)
+	_, ok = ubnd.NoDataInt64()
+	assert.False(t, ok)
+	err = ubnd.SetNoDataInt64(-1)
+	assert.Error(t, err)
+}
+
+func TestNoDataRejected(t *testing.T) {
+	tmpname := tempfile()
+	defer os.Remove(tmpname)
+	ds, _ := Create(GTiff, tmpname, 2, Byte, 2, 2)
+	defer ds.Close()
+	bnds := ds.Bands()
+	err := bnds[0].SetNoData(1)
+	assert.NoError(t, err)
+	//GTiff only supports a single nodata value for all bands
+	err = bnds[1].SetNoData(2)
+	assert.Error(t, err)
+	err = ds.SetNoData(3)
+	assert.NoError(t, err)
+}
+
+func TestMaskInfo(t *testing.T) {
+	ds, _ := Create(Memory, "", 4, Byte, 2, 2)
+	defer ds.Close()
+	mi := ds.MaskInfo()
+	assert.Equal(t, -1, mi.AlphaBand)
+	assert.False(t, mi.PerDatasetMask)
+	require.Len(t, mi.Bands, 4)
+	assert.True(t, mi.Bands[0].AllValid())
+
+	_ = ds.Bands()[1].SetNoData(0)
+	_ = ds.Bands()[3].SetColorInterp(CIAlpha)
+	mi = ds.MaskInfo()
+	assert.Equal(t, 3, mi.AlphaBand)
+	assert.True(t, mi.PerDatasetMask)
+	assert.True(t, mi.Bands[0].UsesAlpha())
+	assert.True(t, mi.Bands[0].UsesPerDatasetMask())
+	assert.False(t, mi.Bands[0].UsesNoData())
+	assert.True(t, mi.Bands[1].UsesNoData())
+	assert.True(t, mi.Bands[1].HasNoData)
+	assert.Equal(t, 0.0, mi.Bands[1].NoData)
+	assert.False(t, mi.Bands[1].UsesPerBandMask())
+
+	mds, _ := Create(Memory, "", 1, Byte, 2, 2)
+	defer mds.Close()
+	_, _ = mds.Bands()[0].CreateMask(0)
+	mi = mds.MaskInfo()
+	assert.True(t, mi.Bands[0].UsesPerBandMask())
+}
+
 func TestOpen(t *testing.T) {
 	_, err := Open("testdata/test.tif", Drivers("MEM"))
 	if err == nil {
@@ -1267,6 +1845,79 @@ func TestOpen(t *testing.T) {
 	}
 }
 
+func TestIdentifyDriver(t *testing.T) {
+	drv, ok, err := IdentifyDriver("testdata/test.tif")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "GTiff", drv.ShortName())
+
+	_, ok, err = IdentifyDriver("testdata/test.tif", VectorOnly())
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	_, ok, _ = IdentifyDriver("testdata/test.tif", RasterOnly(), Drivers("VRT"))
+	assert.False(t, ok)
+	drv, ok, _ = IdentifyDriver("testdata/test.geojson", VectorOnly())
+	assert.True(t, ok)
+	assert.Equal(t, "GeoJSON", drv.ShortName())
+
+	_, ok, err = IdentifyDriver("testdata/nonexistent.tif")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ds, _ := Open("testdata/test.tif")
+	mds, _ := ds.CreateCopy(GTiff, "/vsimem/identify.tif")
+	_ = mds.Close()
+	_ = ds.Close()
+	defer func() { _ = VSIUnlink("/vsimem/identify.tif") }()
+	drv, ok, _ = IdentifyDriver("/vsimem/identify.tif")
+	assert.True(t, ok)
+	assert.Equal(t, "GTiff", drv.ShortName())
+
+	tifdat, _ := ioutil.ReadFile("testdata/test.tif")
+	vpa := vpHandler{datas: make(map[string]KeySizerReaderAt)}
+	vpa.datas["test.tif"] = mbufHandler{tifdat}
+	_ = RegisterVSIHandler("testidentify://", vpa, VSIHandlerStripPrefix(true))
+	drv, ok, _ = IdentifyDriver("testidentify://test.tif", SiblingFiles())
+	assert.True(t, ok)
+	assert.Equal(t, "GTiff", drv.ShortName())
+}
+
+func TestSubdatasets(t *testing.T) {
+	_ = RegisterRaster(GeoPackage)
+	tmpname := "/vsimem/subdatasets.gpkg"
+	defer func() { _ = VSIUnlink(tmpname) }()
+	ds, _ := Open("testdata/test.tif")
+	defer ds.Close()
+	for _, table := range []string{"first", "second"} {
+		cp, err := ds.CreateCopy(GeoPackage, tmpname, CreationOption("RASTER_TABLE="+table, "APPEND_SUBDATASET=YES"))
+		require.NoError(t, err)
+		_ = cp.Close()
+	}
+	assert.Equal(t, []Subdataset{}, ds.Subdatasets())
+	_, err := ds.OpenSubdataset(0)
+	assert.Error(t, err)
+
+	gpkg, err := Open(tmpname, DriverOpenOption("BAND_COUNT=1"))
+	require.NoError(t, err)
+	defer gpkg.Close()
+	sds := gpkg.Subdatasets()
+	require.Len(t, sds, 2)
+	assert.Equal(t, "GPKG:"+tmpname+":first", sds[0].Name)
+	assert.Equal(t, "GPKG:"+tmpname+":second", sds[1].Name)
+	assert.NotEmpty(t, sds[1].Description)
+
+	sd, err := gpkg.OpenSubdataset(1)
+	require.NoError(t, err)
+	assert.Len(t, sd.Bands(), 1)
+	_ = sd.Close()
+	sd, err = gpkg.OpenSubdataset(0, DriverOpenOption("BAND_COUNT=3"))
+	require.NoError(t, err)
+	assert.Len(t, sd.Bands(), 3)
+	_ = sd.Close()
+	_, err = gpkg.OpenSubdataset(2)
+	assert.Error(t, err)
+}
+
 func TestOpenUpdate(t *testing.T) {
 	tt := tempfile()
 	defer os.Remove(tt)
@@ -1388,6 +2039,30 @@ func TestRegister(t *testing.T) {
 	assert.Error(t, err)
 	err = RegisterRaster(GeoJSON)
 	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "GeoJSON driver does not handle rasters")
+
+	//a failing driver does not prevent the registration of the following ones
+	err = RegisterRaster("bogus", HFA, "bogus2")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "GDALRegister_bogus")
+	assert.Contains(t, err.Error(), "GDALRegister_bogus2")
+	_, ok := RasterDriver(HFA)
+	assert.True(t, ok)
+	err = RegisterVector("bogus", CSV)
+	assert.Error(t, err)
+	_, ok = VectorDriver(CSV)
+	assert.True(t, ok)
+
+	err = RegisterRaster(COG, PNG)
+	assert.NoError(t, err)
+	_, ok = RasterDriver(COG)
+	assert.True(t, ok)
+	err = RegisterVector(FlatGeobuf, GeoJSONSeq)
+	assert.NoError(t, err)
+	_, ok = VectorDriver(FlatGeobuf)
+	assert.True(t, ok)
+	err = RegisterVector(PNG)
+	assert.Error(t, err)
 }
 
 func TestTransform(t *testing.T) {
@@ -1653,124 +2328,977 @@ func TestProjMisc(t *testing.T) {
 	assert.True(t, epsg32632.Projected())
 	assert.False(t, epsg32632.EPSGTreatsAsLatLong())
 
-	l, err := NewSpatialRefFromWKT(`LOCAL_CS[,UNIT["m",1]]`)
-	assert.NoError(t, err)
-	ehc := eh()
-	assert.Error(t, l.Validate(ErrLogger(ehc.ErrorHandler)))
-	err = l.AutoIdentifyEPSG()
-	assert.Error(t, err)
-	_, err = l.SemiMajor()
-	assert.Error(t, err)
-	_, err = l.SemiMinor()
+	l, err := NewSpatialRefFromWKT(`LOCAL_CS[,UNIT["m",1]]`)
+	assert.NoError(t, err)
+	ehc := eh()
+	assert.Error(t, l.Validate(ErrLogger(ehc.ErrorHandler)))
+	err = l.AutoIdentifyEPSG()
+	assert.Error(t, err)
+	_, err = l.SemiMajor()
+	assert.Error(t, err)
+	_, err = l.SemiMinor()
+	assert.Error(t, err)
+
+	//TODO? Find a better way to mak WKT() error out
+	l = &SpatialRef{}
+	_, err = l.WKT()
+	assert.Error(t, err)
+}
+
+func TestGeoTransform(t *testing.T) {
+	tmpname := tempfile()
+	defer os.Remove(tmpname)
+	ds, err := Create(GTiff, tmpname, 1, Byte, 20, 20)
+	assert.NoError(t, err)
+	defer ds.Close()
+	_, err = ds.GeoTransform()
+	assert.Error(t, err)
+	ehc := eh()
+	_, err = ds.GeoTransform(ErrLogger(ehc.ErrorHandler))
+	assert.Error(t, err)
+	_, err = ds.Bounds()
+	assert.Error(t, err)
+	ngt := [6]float64{0, 2, 1, 0, 1, 1}
+
+	err = ds.SetGeoTransform(ngt)
+	assert.NoError(t, err)
+	ehc = eh()
+	err = ds.SetGeoTransform(ngt, ErrLogger(ehc.ErrorHandler))
+	assert.NoError(t, err)
+
+	gt, err := ds.GeoTransform()
+	assert.NoError(t, err)
+	assert.Equal(t, gt, ngt)
+	ehc = eh()
+	_, err = ds.GeoTransform(ErrLogger(ehc.ErrorHandler))
+	assert.NoError(t, err)
+}
+
+func TestGeometryTransform(t *testing.T) {
+	sr, _ := NewSpatialRefFromEPSG(4326)
+	srm, _ := NewSpatialRefFromEPSG(3857)
+	gp, _ := NewGeometryFromWKT("POINT (10 10)", sr)
+	assert.True(t, gp.SpatialRef().IsSame(sr))
+
+	err := gp.Reproject(srm)
+	assert.NoError(t, err)
+	assert.True(t, gp.SpatialRef().IsSame(srm))
+	gp.Close()
+
+	ehc := eh()
+	gp, _ = NewGeometryFromWKT("POINT (10 10)", sr)
+	err = gp.Reproject(srm, ErrLogger(ehc.ErrorHandler))
+	assert.NoError(t, err)
+
+	nwkt, _ := gp.WKT()
+	assert.NotEqual(t, "POINT (10 10)", nwkt)
+	gp.SetSpatialRef(sr)
+	assert.True(t, gp.SpatialRef().IsSame(sr))
+
+	gp.Close()
+
+	gp, _ = NewGeometryFromWKT("POINT (10 91)", sr)
+	err = gp.Reproject(srm)
+	assert.Error(t, err)
+	gp.Close()
+
+	ehc = eh()
+	gp, _ = NewGeometryFromWKT("POINT (10 91)", sr, ErrLogger(ehc.ErrorHandler))
+	err = gp.Reproject(srm)
+	assert.Error(t, err)
+	gp.Close()
+
+	trn, _ := NewTransform(sr, srm)
+	gp, _ = NewGeometryFromWKT("POINT (10 10)", nil)
+
+	err = gp.Transform(trn)
+	assert.NoError(t, err)
+	assert.True(t, gp.SpatialRef().IsSame(srm))
+	nwkt, _ = gp.WKT()
+	assert.NotEqual(t, "POINT (10 10)", nwkt)
+	gp.Close()
+
+	ehc = eh()
+	gp, _ = NewGeometryFromWKT("POINT (10 10)", nil)
+	err = gp.Transform(trn, ErrLogger(ehc.ErrorHandler))
+	assert.NoError(t, err)
+	gp.Close()
+
+	gp, _ = NewGeometryFromWKT("POINT (10 91)", sr)
+	err = gp.Transform(trn)
+	assert.Error(t, err)
+	gp.Close()
+
+	ehc = eh()
+	gp, _ = NewGeometryFromWKT("POINT (10 91)", sr)
+	err = gp.Transform(trn, ErrLogger(ehc.ErrorHandler))
+	assert.Error(t, err)
+	gp.Close()
+}
+
+func TestProjBounds(t *testing.T) {
+	sr4326, _ := NewSpatialRefFromEPSG(4326)
+	sr3857, _ := NewSpatialRefFromEPSG(3857)
+	box, err := NewGeometryFromWKT("POLYGON((-180 -91,-180 90,180 90,180 -90,-180 -91))", sr4326)
+	assert.NoError(t, err)
+	_, err = box.Bounds(sr3857)
+	assert.Error(t, err)
+	_, err = box.Bounds(&SpatialRef{handle: nil})
+	assert.Error(t, err)
+
+}
+
+func TestReprojectBounds(t *testing.T) {
+	sr4326, _ := NewSpatialRefFromEPSG(4326)
+	defer sr4326.Close()
+	utm, _ := NewSpatialRefFromEPSG(32631)
+	defer utm.Close()
+	polar, _ := NewSpatialRefFromEPSG(3413)
+	defer polar.Close()
+	pacific, _ := NewSpatialRefFromEPSG(3832)
+	defer pacific.Close()
+
+	fallback := func(bnds [4]float64, src, dst *SpatialRef, densifyPts int) [4]float64 {
+		trn, err := NewTransform(src, dst)
+		require.NoError(t, err)
+		defer trn.Close()
+		ret, err := densifiedBounds(trn, bnds, src, dst, densifyPts)
+		require.NoError(t, err)
+		return ret
+	}
+
+	// UTM→WGS84: the northernmost point is in the middle of the top edge
+	ubnds := [4]float64{300000, 5000000, 700000, 5500000}
+	corners, err := reprojectBounds(ubnds, utm, sr4326, 0, nil)
+	assert.NoError(t, err)
+	dense, err := reprojectBounds(ubnds, utm, sr4326, defaultDensifyPts, nil)
+	assert.NoError(t, err)
+	assert.Greater(t, dense[3], corners[3]+0.01)
+	assert.InDelta(t, 49.653, dense[3], 0.01) //latitude of x=500000,y=5500000
+	fb := fallback(ubnds, utm, sr4326, defaultDensifyPts)
+	assert.InDeltaSlice(t, dense[:], fb[:], 1e-3)
+	_, err = reprojectBounds(ubnds, utm, sr4326, -1, nil)
+	assert.Error(t, err)
+
+	// polar stereographic box containing the north pole
+	pbnds := [4]float64{-1000000, -1000000, 1000000, 1000000}
+	ret, err := reprojectBounds(pbnds, polar, sr4326, defaultDensifyPts, nil)
+	assert.NoError(t, err)
+	for _, ret := range [][4]float64{ret, fallback(pbnds, polar, sr4326, defaultDensifyPts)} {
+		assert.Equal(t, -180.0, ret[0])
+		assert.Equal(t, 180.0, ret[2])
+		assert.Equal(t, 90.0, ret[3])
+		assert.InDelta(t, 77.0, ret[1], 0.5) //latitude of the corners
+	}
+
+	// box centered on the antimeridian, from 170°E to 170°W
+	deg := 111319.49079327357
+	abnds := [4]float64{20 * deg, 0, 40 * deg, 1000000}
+	ret, err = reprojectBounds(abnds, pacific, sr4326, defaultDensifyPts, nil)
+	assert.NoError(t, err)
+	for _, ret := range [][4]float64{ret, fallback(abnds, pacific, sr4326, defaultDensifyPts)} {
+		assert.InDelta(t, 170, ret[0], 1e-6)
+		assert.InDelta(t, -170, ret[2], 1e-6)
+		assert.Greater(t, ret[0], ret[2])
+	}
+	// and back, starting from a geographic box crossing the antimeridian
+	back := fallback([4]float64{170, 0, -170, 5}, sr4326, pacific, defaultDensifyPts)
+	assert.InDelta(t, 20*deg, back[0], 1e-3)
+	assert.InDelta(t, 40*deg, back[2], 1e-3)
+
+	ds, _ := Create(Memory, "", 1, Byte, 200, 100)
+	defer ds.Close()
+	_ = ds.SetSpatialRef(pacific)
+	_ = ds.SetGeoTransform([6]float64{20 * deg, 20 * deg / 200, 0, 1000000, 0, -10000})
+	bnds, err := ds.Bounds(sr4326)
+	assert.NoError(t, err)
+	assert.InDelta(t, 170, bnds[0], 1e-6)
+	assert.InDelta(t, -170, bnds[2], 1e-6)
+	bnds, err = ds.Bounds(sr4326, DensifyPoints(0))
+	assert.NoError(t, err)
+	assert.InDelta(t, 170, bnds[0], 1e-6)
+	_, err = ds.Bounds(sr4326, DensifyPoints(-5))
+	assert.Error(t, err)
+}
+
+func TestAxisMapping(t *testing.T) {
+	assert.Equal(t, TraditionalGISOrder, DefaultAxisMappingStrategy())
+	trad, _ := NewSpatialRefFromEPSG(4326)
+	defer trad.Close()
+	assert.Equal(t, TraditionalGISOrder, trad.AxisMappingStrategy())
+	assert.Equal(t, []int{2, 1}, trad.DataAxisToSRSAxisMapping())
+	assert.True(t, trad.longitudeFirst())
+	auth, _ := NewSpatialRefFromEPSG(4326, AxisMapping(AuthorityCompliant))
+	defer auth.Close()
+	assert.Equal(t, AuthorityCompliant, auth.AxisMappingStrategy())
+	assert.Equal(t, []int{1, 2}, auth.DataAxisToSRSAxisMapping())
+	assert.False(t, auth.longitudeFirst())
+	sr3857, _ := NewSpatialRefFromEPSG(3857)
+	defer sr3857.Close()
+
+	deg := 111319.49079327357
+	trn, err := NewTransform(auth, sr3857)
+	require.NoError(t, err)
+	x, y := []float64{10}, []float64{20} //lat,lon
+	assert.NoError(t, trn.TransformEx(x, y, nil, nil))
+	assert.InDelta(t, 20*deg, x[0], 1e-3)
+	trn.Close()
+
+	pt, _ := NewGeometryFromWKT("POINT (10 20)", auth)
+	defer pt.Close()
+	assert.NoError(t, pt.Reproject(sr3857))
+	bnds, _ := pt.Bounds()
+	assert.InDelta(t, 20*deg, bnds[0], 1e-3)
+
+	SetDefaultAxisMappingStrategy(AuthorityCompliant)
+	func() {
+		defer SetDefaultAxisMappingStrategy(TraditionalGISOrder)
+		sr, _ := NewSpatialRef("EPSG:4326")
+		defer sr.Close()
+		assert.Equal(t, []int{1, 2}, sr.DataAxisToSRSAxisMapping())
+		sr2, _ := NewSpatialRef("EPSG:4326", AxisMapping(TraditionalGISOrder))
+		defer sr2.Close()
+		assert.Equal(t, []int{2, 1}, sr2.DataAxisToSRSAxisMapping())
+	}()
+	assert.Equal(t, TraditionalGISOrder, DefaultAxisMappingStrategy())
+
+	// bounds reprojected to a lat,lon SpatialRef cross the antimeridian on the second axis
+	pacific, _ := NewSpatialRefFromEPSG(3832)
+	defer pacific.Close()
+	abnds := [4]float64{20 * deg, 0, 40 * deg, 1000000}
+	ret, err := reprojectBounds(abnds, pacific, auth, defaultDensifyPts, nil)
+	assert.NoError(t, err)
+	assert.InDelta(t, 170, ret[1], 1e-6)
+	assert.InDelta(t, -170, ret[3], 1e-6)
+	trn, _ = NewTransform(pacific, auth)
+	ret, err = densifiedBounds(trn, abnds, pacific, auth, defaultDensifyPts)
+	trn.Close()
+	assert.NoError(t, err)
+	assert.InDelta(t, 170, ret[1], 1e-6)
+	assert.InDelta(t, -170, ret[3], 1e-6)
+	assert.InDelta(t, 0, ret[0], 1e-6)
+
+	trad.SetAxisMappingStrategy(AuthorityCompliant)
+	assert.Equal(t, []int{1, 2}, trad.DataAxisToSRSAxisMapping())
+}
+
+func TestSpatialRefExports(t *testing.T) {
+	sr, _ := NewSpatialRefFromEPSG(32631)
+	defer sr.Close()
+	sr4326, _ := NewSpatialRefFromEPSG(4326)
+	defer sr4326.Close()
+
+	wkt1, err := sr.WKT()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(wkt1, `PROJCS["WGS 84 / UTM zone 31N"`))
+	wkt, err := sr.WKT(WKTFormat(WKT1))
+	assert.NoError(t, err)
+	assert.Equal(t, wkt1, wkt)
+	wkt, err = sr.WKT(WKTFormat(WKT2_2019), ErrLogger(eh().ErrorHandler))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(wkt, `PROJCRS["WGS 84 / UTM zone 31N"`))
+	assert.NotContains(t, wkt, "\n")
+	wkt, err = sr.WKT(WKTFormat(WKT2_2015), Multiline())
+	assert.NoError(t, err)
+	assert.Contains(t, wkt, "\n")
+	wkt, err = sr.WKT(WKTFormat(WKT1ESRI))
+	assert.NoError(t, err)
+	assert.Contains(t, wkt, "WGS_1984_UTM_Zone_31N")
+	_, err = sr.WKT(WKTFormat("bogus"))
+	assert.Error(t, err)
+
+	pj, err := sr.Proj4(ErrLogger(eh().ErrorHandler))
+	assert.NoError(t, err)
+	assert.Equal(t, "+proj=utm +zone=31 +datum=WGS84 +units=m +no_defs", strings.TrimSpace(pj))
+
+	pjj, err := sr.PROJJSON()
+	assert.NoError(t, err)
+	assert.NotContains(t, pjj, "\n")
+	assert.Contains(t, pjj, `"name":"WGS 84 / UTM zone 31N"`)
+	pjjm, err := sr.PROJJSON(Multiline(), ErrLogger(eh().ErrorHandler))
+	assert.NoError(t, err)
+	assert.Contains(t, pjjm, "\n")
+	back, err := NewSpatialRefFromPROJJSON(pjj, ErrLogger(eh().ErrorHandler))
+	assert.NoError(t, err)
+	assert.True(t, back.IsSame(sr))
+	assert.Equal(t, []int{1, 2}, back.DataAxisToSRSAxisMapping())
+	back.Close()
+	_, err = NewSpatialRefFromPROJJSON("EPSG:4326")
+	assert.Error(t, err)
+	_, err = NewSpatialRefFromPROJJSON(`{"type":"bogus"}`)
+	assert.Error(t, err)
+
+	assert.Equal(t, "WGS 84 / UTM zone 31N", sr.Name())
+	assert.Equal(t, "WGS 84", sr4326.Name())
+	name, factor := sr.LinearUnits()
+	assert.Equal(t, "metre", name)
+	assert.Equal(t, 1.0, factor)
+	name, factor = sr4326.AngularUnits()
+	assert.Equal(t, "degree", name)
+	assert.InDelta(t, math.Pi/180, factor, 1e-12)
+	ft, _ := NewSpatialRefFromEPSG(2227) //California zone 3 (ftUS)
+	defer ft.Close()
+	_, factor = ft.LinearUnits()
+	assert.InDelta(t, 0.3048006096, factor, 1e-9)
+
+	aou, ok := sr.AreaOfUse()
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, aou.West)
+	assert.Equal(t, 6.0, aou.East)
+	assert.Equal(t, 0.0, aou.South)
+	assert.Equal(t, 84.0, aou.North)
+	assert.NotEmpty(t, aou.Name)
+	local, _ := NewSpatialRef(`LOCAL_CS["arbitrary"]`)
+	defer local.Close()
+	_, ok = local.AreaOfUse()
+	assert.False(t, ok)
+}
+
+func TestCoordinateOperations(t *testing.T) {
+	nad27, _ := NewSpatialRefFromEPSG(4267)
+	defer nad27.Close()
+	wgs84, _ := NewSpatialRefFromEPSG(4326)
+	defer wgs84.Close()
+	sr3857, _ := NewSpatialRefFromEPSG(3857)
+	defer sr3857.Close()
+
+	ops, err := CoordinateOperations(nad27, wgs84, ErrLogger(eh().ErrorHandler))
+	require.NoError(t, err)
+	require.Greater(t, len(ops), 1)
+	for _, op := range ops {
+		assert.NotEmpty(t, op.Name)
+	}
+	//restrict to Texas
+	tops, err := CoordinateOperations(nad27, wgs84, AreaOfInterest(-106, 26, -94, 36))
+	require.NoError(t, err)
+	assert.Less(t, len(tops), len(ops))
+	for _, op := range tops {
+		if op.AreaOfUse != nil {
+			assert.True(t, op.AreaOfUse.West <= -94 || op.AreaOfUse.West > op.AreaOfUse.East, op.Name)
+			assert.True(t, op.AreaOfUse.South <= 36, op.Name)
+		}
+	}
+	aops, err := CoordinateOperations(nad27, wgs84, DesiredAccuracy(5))
+	require.NoError(t, err)
+	for _, op := range aops {
+		assert.True(t, op.Accuracy <= 5, op.Name)
+	}
+	_, err = CoordinateOperations(nil, wgs84)
+	assert.Error(t, err)
+
+	trn, err := NewTransform(wgs84, sr3857, AreaOfInterest(-10, 40, 10, 50), AllowBallpark(false), ErrLogger(eh().ErrorHandler))
+	require.NoError(t, err)
+	op, err := trn.EstimatedOperation()
+	assert.NoError(t, err)
+	assert.Contains(t, op.Name, "Pseudo-Mercator")
+	assert.Contains(t, op.Proj, "+proj=webmerc")
+	assert.Equal(t, 0.0, op.Accuracy)
+	trn.Close()
+
+	trn, err = NewTransform(nad27, wgs84, AreaOfInterest(-106, 26, -94, 36))
+	require.NoError(t, err)
+	op, err = trn.EstimatedOperation()
+	assert.NoError(t, err)
+	assert.True(t, op.Instantiable)
+	trn.Close()
+
+	trn, err = NewTransform(nil, nil, ProjPipeline("+proj=pipeline +step +proj=axisswap +order=2,1", false))
+	require.NoError(t, err)
+	x, y := []float64{1}, []float64{2}
+	assert.NoError(t, trn.TransformEx(x, y, nil, nil))
+	assert.Equal(t, []float64{2}, x)
+	assert.Equal(t, []float64{1}, y)
+	op, err = trn.EstimatedOperation()
+	assert.NoError(t, err)
+	assert.Contains(t, op.Proj, "axisswap")
+	trn.Close()
+
+	_, err = NewTransform(nil, nil, ProjPipeline("+proj=bogus", false))
+	assert.Error(t, err)
+	_, err = NewTransform(wgs84, sr3857, DesiredAccuracy(-1), ProjPipeline("bogus", true), ErrLogger(eh().ErrorHandler))
+	assert.Error(t, err)
+}
+
+func TestTransform4D(t *testing.T) {
+	itrf, _ := NewSpatialRefFromEPSG(7789) //ITRF2014 geocentric
+	defer itrf.Close()
+	etrf, _ := NewSpatialRefFromEPSG(7930) //ETRF2000 geocentric
+	defer etrf.Close()
+	trn, err := NewTransform(itrf, etrf)
+	require.NoError(t, err)
+	defer trn.Close()
+
+	x := []float64{4201000, 4201000}
+	y := []float64{178000, 178000}
+	z := []float64{4779000, 4779000}
+	epochs := []float64{2010, 2020}
+	oks := make([]bool, 2)
+	err = trn.TransformEx4D(x, y, z, epochs, oks)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, true}, oks)
+	d := math.Sqrt((x[1]-x[0])*(x[1]-x[0]) + (y[1]-y[0])*(y[1]-y[0]) + (z[1]-z[0])*(z[1]-z[0]))
+	assert.Greater(t, d, 0.05) //plate motion over 10 years
+	assert.Less(t, d, 1.0)
+	assert.Error(t, trn.TransformEx4D(x, y, z, epochs[0:1], nil))
+
+	// epoch taken from the source SpatialRef
+	assert.Equal(t, 0.0, itrf.CoordinateEpoch())
+	require.NoError(t, itrf.SetCoordinateEpoch(2010, ErrLogger(eh().ErrorHandler)))
+	assert.Equal(t, 2010.0, itrf.CoordinateEpoch())
+	trn2010, err := NewTransform(itrf, etrf)
+	require.NoError(t, err)
+	defer trn2010.Close()
+	x2, y2, z2 := []float64{4201000}, []float64{178000}, []float64{4779000}
+	assert.NoError(t, trn2010.TransformEx4D(x2, y2, z2, nil, nil))
+	assert.InDelta(t, x[0], x2[0], 1e-6)
+	assert.InDelta(t, z[0], z2[0], 1e-6)
+
+	// propagation through datasets and layers
+	geog, _ := NewSpatialRefFromEPSG(9000) //ITRF2014 geographic 2D
+	defer geog.Close()
+	require.NoError(t, geog.SetCoordinateEpoch(2021.5))
+	tmpname := tempfile()
+	defer os.Remove(tmpname)
+	ds, _ := Create(GTiff, tmpname, 1, Byte, 16, 16)
+	_ = ds.SetGeoTransform([6]float64{2, 0.1, 0, 48, 0, -0.1})
+	assert.NoError(t, ds.SetSpatialRef(geog))
+	_ = ds.Close()
+	ds, _ = Open(tmpname)
+	assert.Equal(t, 2021.5, ds.SpatialRef().CoordinateEpoch())
+	_ = ds.Close()
+
+	vds, _ := CreateVector(Memory, "")
+	defer vds.Close()
+	lyr, err := vds.CreateLayer("l", geog, GTPoint)
+	require.NoError(t, err)
+	assert.Equal(t, 2021.5, lyr.SpatialRef().CoordinateEpoch())
+	require.NoError(t, geog.SetCoordinateEpoch(0))
+	assert.Equal(t, 0.0, geog.CoordinateEpoch())
+}
+
+func TestCompoundSpatialRef(t *testing.T) {
+	wgs84, _ := NewSpatialRefFromEPSG(4326)
+	defer wgs84.Close()
+	egm96, _ := NewSpatialRefFromEPSG(5773)
+	defer egm96.Close()
+	assert.False(t, wgs84.IsCompound())
+	assert.False(t, wgs84.IsVertical())
+	assert.False(t, wgs84.Is3D())
+	assert.True(t, egm96.IsVertical())
+
+	cmp, err := NewCompoundSpatialRef("", wgs84, egm96)
+	require.NoError(t, err)
+	defer cmp.Close()
+	assert.True(t, cmp.IsCompound())
+	assert.True(t, cmp.IsVertical())
+	assert.True(t, cmp.Is3D())
+	assert.False(t, cmp.IsGeocentric())
+	assert.Equal(t, "WGS 84 + EGM96 height", cmp.Name())
+	ref, _ := NewSpatialRef("EPSG:4326+5773")
+	defer ref.Close()
+	assert.True(t, cmp.IsSame(ref))
+
+	_, err = NewCompoundSpatialRef("bad", egm96, wgs84, ErrLogger(eh().ErrorHandler))
+	assert.Error(t, err)
+	_, err = NewCompoundSpatialRef("", nil, egm96)
+	assert.Error(t, err)
+	_, err = NewCompoundSpatialRef("", wgs84, &SpatialRef{})
+	assert.Error(t, err)
+
+	geoc, _ := NewSpatialRefFromEPSG(4978)
+	defer geoc.Close()
+	assert.True(t, geoc.IsGeocentric())
+	assert.True(t, geoc.Is3D())
+
+	sr, _ := NewSpatialRefFromEPSG(4326)
+	defer sr.Close()
+	require.NoError(t, sr.PromoteTo3D(""))
+	assert.True(t, sr.Is3D())
+	assert.Equal(t, "4979", sr.AuthorityCode(""))
+	require.NoError(t, sr.DemoteTo2D("", ErrLogger(eh().ErrorHandler)))
+	assert.False(t, sr.Is3D())
+	assert.True(t, sr.IsSame(wgs84))
+	require.NoError(t, sr.PromoteTo3D("my3d", ErrLogger(eh().ErrorHandler)))
+	assert.Equal(t, "my3d", sr.Name())
+
+	// ellipsoidal to orthometric heights
+	trn, err := NewTransform(sr, cmp)
+	require.NoError(t, err)
+	defer trn.Close()
+	if op, err := trn.EstimatedOperation(); err != nil || !strings.Contains(op.Proj, "vgridshift") {
+		t.Skip("EGM96 geoid grid not available")
+	}
+	x, y, z := []float64{2}, []float64{48}, []float64{100}
+	require.NoError(t, trn.TransformEx(x, y, z, nil))
+	assert.InDelta(t, 100-44.6, z[0], 1) //EGM96 undulation around Paris is ~44.6m
+}
+
+func TestCRSDatabase(t *testing.T) {
+	all, err := ListCRS(Authority("EPSG"))
+	require.NoError(t, err)
+	found := false
+	for _, info := range all {
+		assert.Equal(t, "EPSG", info.Authority)
+		if info.Code == "4326" {
+			found = true
+			assert.Equal(t, "WGS 84", info.Name)
+			assert.Equal(t, CRSGeographic2D, info.Type)
+			assert.Equal(t, "Geographic2D", info.Type.String())
+			assert.False(t, info.Deprecated)
+			require.NotNil(t, info.AreaOfUse)
+			assert.Equal(t, -180.0, info.AreaOfUse.West)
+		}
+	}
+	assert.True(t, found)
+
+	utm, err := ListCRS(Authority("EPSG"), NameContains("wgs 84 / utm zone 31n"), CRSTypes(CRSProjected, CRSCompound))
+	require.NoError(t, err)
+	require.NotEmpty(t, utm)
+	assert.Equal(t, "32631", utm[0].Code)
+	assert.Equal(t, "Transverse Mercator", utm[0].ProjectionMethod)
+
+	l93, _ := ListCRS(Authority("EPSG"), NameContains("Lambert-93"), AreaOfInterest(2, 48, 3, 49), ErrLogger(eh().ErrorHandler))
+	assert.NotEmpty(t, l93)
+	l93, _ = ListCRS(Authority("EPSG"), NameContains("Lambert-93"), AreaOfInterest(170, -10, -170, 10))
+	assert.Empty(t, l93)
+
+	assert.True(t, lonLatIntersects([4]float64{170, -10, -170, 10}, [4]float64{-175, 0, -160, 5}))
+	assert.False(t, lonLatIntersects([4]float64{170, -10, -170, 10}, [4]float64{-160, 0, -150, 5}))
+	assert.False(t, lonLatIntersects([4]float64{0, 0, 10, 10}, [4]float64{0, 20, 10, 30}))
+
+	sr, _ := NewSpatialRefFromProj4("+proj=utm +zone=31 +datum=WGS84 +units=m +no_defs")
+	defer sr.Close()
+	matches, err := sr.FindMatches()
+	require.NoError(t, err)
+	require.NotEmpty(t, matches)
+	assert.Equal(t, "EPSG", matches[0].Authority)
+	assert.Equal(t, "32631", matches[0].Code)
+	assert.Greater(t, matches[0].Confidence, 0)
+	assert.Equal(t, "", sr.AuthorityCode(""))
+
+	wgs84, _ := NewSpatialRefFromEPSG(4326)
+	defer wgs84.Close()
+	wkt, _ := wgs84.WKT()
+	sr2, _ := NewSpatialRefFromWKT(wkt)
+	defer sr2.Close()
+	matches, _ = sr2.FindMatches(Authority("epsg"), ErrLogger(eh().ErrorHandler))
+	require.NotEmpty(t, matches)
+	assert.Equal(t, "4326", matches[0].Code)
+	assert.Equal(t, 100, matches[0].Confidence)
+	matches, _ = sr2.FindMatches(Authority("ESRI"))
+	assert.Empty(t, matches)
+}
+
+func TestSpatialRefSameAndClone(t *testing.T) {
+	gis, _ := NewSpatialRefFromEPSG(4326)
+	defer gis.Close()
+	auth, _ := NewSpatialRefFromEPSG(4326, AxisMapping(AuthorityCompliant))
+	defer auth.Close()
+	assert.False(t, gis.IsSame(auth))
+	assert.True(t, gis.IsSame(auth, IgnoreAxisMapping()))
+
+	wkt, _ := gis.WKT()
+	renamed, _ := NewSpatialRefFromWKT(strings.Replace(wkt, "WGS 84", "my crs", 1))
+	defer renamed.Close()
+	assert.True(t, gis.IsSame(renamed))
+	assert.True(t, gis.IsSame(renamed, Criterion(SameEquivalent)))
+	assert.False(t, gis.IsSame(renamed, Criterion(SameStrict)))
+
+	itrf, _ := NewSpatialRefFromEPSG(9000)
+	defer itrf.Close()
+	cl := itrf.Clone()
+	defer cl.Close()
+	assert.True(t, itrf.IsSame(cl, Criterion(SameStrict)))
+	require.NoError(t, cl.SetCoordinateEpoch(2020))
+	assert.Equal(t, 0.0, itrf.CoordinateEpoch())
+	assert.False(t, itrf.IsSame(cl))
+	assert.True(t, itrf.IsSame(cl, IgnoreCoordinateEpoch()))
+	cl2 := auth.Clone()
+	defer cl2.Close()
+	assert.Equal(t, AuthorityCompliant, cl2.AxisMappingStrategy())
+
+	utm, _ := NewSpatialRefFromEPSG(32631)
+	defer utm.Close()
+	geog, err := utm.CloneGeogCS()
+	require.NoError(t, err)
+	defer geog.Close()
+	assert.True(t, geog.IsSame(gis))
+	vert, _ := NewSpatialRefFromEPSG(5773)
+	defer vert.Close()
+	_, err = vert.CloneGeogCS(ErrLogger(eh().ErrorHandler))
+	assert.Error(t, err)
+
+	cmp, _ := NewSpatialRef("EPSG:4326+5773")
+	defer cmp.Close()
+	require.NoError(t, cmp.StripVertical(ErrLogger(eh().ErrorHandler)))
+	assert.False(t, cmp.IsCompound())
+	assert.True(t, cmp.IsSame(gis))
+
+	done := make(chan string)
+	for i := 0; i < 4; i++ {
+		local := utm.Clone()
+		go func() {
+			defer local.Close()
+			for j := 0; j < 10; j++ {
+				_, _ = local.WKT()
+			}
+			done <- local.AuthorityCode("")
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, "32631", <-done)
+	}
+}
+
+func TestTransformCloneAndBatch(t *testing.T) {
+	wgs84, _ := NewSpatialRefFromEPSG(4326)
+	defer wgs84.Close()
+	merc, _ := NewSpatialRefFromEPSG(3857)
+	defer merc.Close()
+	trn, err := NewTransform(wgs84, merc)
+	require.NoError(t, err)
+
+	n := 600 //more than one chunk
+	x, y := make([]float64, n), make([]float64, n)
+	xy := make([][2]float64, n)
+	xyz := make([][3]float64, n)
+	xyzm := make([]float64, 4*n)
+	for i := 0; i < n; i++ {
+		x[i], y[i] = -180+float64(i)*0.5, -80+float64(i)*0.25
+		xy[i] = [2]float64{x[i], y[i]}
+		xyz[i] = [3]float64{x[i], y[i], 10}
+		copy(xyzm[4*i:], []float64{x[i], y[i], 10, 42})
+	}
+	require.NoError(t, trn.TransformEx(x, y, nil, nil))
+	oks := make([]bool, n)
+	require.NoError(t, trn.TransformXY(xy, oks))
+	require.NoError(t, trn.TransformXYZ(xyz, nil))
+	require.NoError(t, trn.TransformInterleaved(xyzm, 4, nil))
+	for i := 0; i < n; i++ {
+		assert.True(t, oks[i])
+		assert.Equal(t, x[i], xy[i][0])
+		assert.Equal(t, y[i], xy[i][1])
+		assert.Equal(t, x[i], xyz[i][0])
+		assert.Equal(t, 10.0, xyz[i][2])
+		assert.Equal(t, y[i], xyzm[4*i+1])
+		assert.Equal(t, 42.0, xyzm[4*i+3])
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		_ = trn.TransformXY(xy[0:10], oks[0:10])
+	})
+	assert.Equal(t, 0.0, allocs)
+
+	bad := [][2]float64{{2, 48}, {2, 95}}
+	err = trn.TransformXY(bad, oks[0:2])
 	assert.Error(t, err)
+	assert.Equal(t, []bool{true, false}, oks[0:2])
+	assert.Error(t, trn.TransformXY(bad, oks))
+	assert.Error(t, trn.TransformInterleaved(xyzm, 1, nil))
+	assert.Error(t, trn.TransformInterleaved(xyzm[0:7], 2, nil))
+	assert.NoError(t, trn.TransformXY(nil, nil))
 
-	//TODO? Find a better way to mak WKT() error out
-	l = &SpatialRef{}
-	_, err = l.WKT()
+	cl, err := trn.Clone(ErrLogger(eh().ErrorHandler))
+	require.NoError(t, err)
+	trn.Close()
+	pt := [][2]float64{{2, 48}}
+	require.NoError(t, cl.TransformXY(pt, nil))
+	assert.InDelta(t, 222638.98, pt[0][0], 0.01)
+	geom, _ := NewGeometryFromWKT("POINT (2 48)", wgs84)
+	defer geom.Close()
+	require.NoError(t, geom.Transform(cl))
+	assert.True(t, geom.SpatialRef().IsSame(merc))
+	cl.Close()
+
+	pool, err := NewTransformPool(wgs84, merc)
+	require.NoError(t, err)
+	done := make(chan error)
+	for i := 0; i < 8; i++ {
+		go func() {
+			for j := 0; j < 10; j++ {
+				trn, err := pool.Get()
+				if err != nil {
+					done <- err
+					return
+				}
+				pts := [][2]float64{{2, 48}}
+				err = trn.TransformXY(pts, nil)
+				pool.Put(trn)
+				if err != nil {
+					done <- err
+					return
+				}
+			}
+			done <- nil
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		assert.NoError(t, <-done)
+	}
+	trns := make([]*Transform, pool.maxIdle+2)
+	for i := range trns {
+		trns[i], err = pool.Get()
+		require.NoError(t, err)
+	}
+	for _, trn := range trns {
+		pool.Put(trn)
+	}
+	assert.Len(t, pool.idle, pool.maxIdle)
+	assert.Nil(t, trns[len(trns)-1].handle)
+
+	inuse, _ := pool.Get()
+	pool.Close()
+	pool.Close()
+	_, err = pool.Get()
 	assert.Error(t, err)
+	pool.Put(inuse)
+	assert.Nil(t, inuse.handle)
 }
 
-func TestGeoTransform(t *testing.T) {
-	tmpname := tempfile()
-	defer os.Remove(tmpname)
-	ds, err := Create(GTiff, tmpname, 1, Byte, 20, 20)
-	assert.NoError(t, err)
+func TestEWKB(t *testing.T) {
+	wgs84, _ := NewSpatialRefFromEPSG(4326)
+	defer wgs84.Close()
+	g, _ := NewGeometryFromWKT("POINT ZM (1 2 3 4)", wgs84)
+	defer g.Close()
+	b, err := g.EWKB()
+	require.NoError(t, err)
+	assert.Equal(t, "01010000e0e6100000", hex.EncodeToString(b[0:9]))
+	g2, err := NewGeometryFromEWKB(b)
+	require.NoError(t, err)
+	wkt, _ := g2.WKT()
+	assert.Equal(t, "POINT ZM (1 2 3 4)", wkt)
+	assert.True(t, g2.SpatialRef().IsSame(wgs84))
+	g2.Close()
+
+	g3, _ := NewGeometryFromWKT("LINESTRING M (1 2 3,4 5 6)", nil)
+	defer g3.Close()
+	b, err = g3.EWKB(ErrLogger(eh().ErrorHandler))
+	require.NoError(t, err)
+	assert.Equal(t, "0102000040", hex.EncodeToString(b[0:5]))
+	g4, err := NewGeometryFromEWKB(b, ErrLogger(eh().ErrorHandler))
+	require.NoError(t, err)
+	wkt, _ = g4.WKT()
+	assert.Equal(t, "LINESTRING M (1 2 3,4 5 6)", wkt)
+	assert.Nil(t, g4.SpatialRef().handle)
+	g4.Close()
+
+	//unknown epsg code
+	b, _ = hex.DecodeString("0101000020010000000000000000000000000000000000f03f")
+	_, err = NewGeometryFromEWKB(b, ErrLogger(eh().ErrorHandler))
+	assert.Error(t, err)
+	_, err = NewGeometryFromEWKB(b[0:10])
+	assert.Error(t, err)
+
+	//explicit epsg codes, or exact epsg matches are used as srid
+	assert.Equal(t, 0, srid(nil))
+	assert.Equal(t, 4326, srid(wgs84))
+	ortho, _ := NewSpatialRefFromProj4("+proj=ortho +lat_0=10 +lon_0=20 +datum=WGS84")
+	defer ortho.Close()
+	assert.Equal(t, 0, srid(ortho))
+	utm, _ := NewSpatialRefFromProj4("+proj=utm +zone=31 +datum=WGS84 +units=m +no_defs")
+	defer utm.Close()
+	assert.Equal(t, 0, srid(utm))
+	epsg32631, _ := NewSpatialRefFromEPSG(32631)
+	defer epsg32631.Close()
+	simple, _ := epsg32631.WKT(WKTFormat(WKT1Simple))
+	assert.NotContains(t, simple, "AUTHORITY")
+	noauth, _ := NewSpatialRef(simple)
+	defer noauth.Close()
+	assert.Equal(t, 32631, srid(noauth))
+
+	ds, _ := CreateVector(Memory, "")
 	defer ds.Close()
-	_, err = ds.GeoTransform()
-	assert.Error(t, err)
-	ehc := eh()
-	_, err = ds.GeoTransform(ErrLogger(ehc.ErrorHandler))
+	lyr, err := ds.CreateLayer("l", wgs84, GTPoint25D)
+	require.NoError(t, err)
+	p1, _ := NewGeometryFromWKT("POINT Z (1 2 3)", nil)
+	b1, _ := p1.EWKB()
+	p1.Close()
+	fids, err := lyr.NewFeaturesFromEWKB([][]byte{b1, nil, b}, ErrLogger(eh().ErrorHandler))
+	assert.Error(t, err) //unknown epsg code
+	assert.Len(t, fids, 2)
+	b3, _ := hex.DecodeString("01010000a0e6100000" + "0000000000001040" + "0000000000001440" + "0000000000001840")
+	fids, err = lyr.NewFeaturesFromEWKB([][]byte{b3})
+	require.NoError(t, err)
+	require.Len(t, fids, 1)
+	b4, _ := hex.DecodeString("01010000a0110f0000" + "0000000000001040" + "0000000000001440" + "0000000000001840")
+	_, err = lyr.NewFeaturesFromEWKB([][]byte{b4})
+	assert.Error(t, err) //srid differs from the layer's
+	_, err = lyr.NewFeaturesFromEWKB([][]byte{{}})
+	assert.Error(t, err)
+
+	_ = lyr.NextFeature()
+	all := [][]byte{}
+	allFids := []int64{}
+	err = lyr.ForEachEWKB(func(fid int64, b []byte) error {
+		allFids = append(allFids, fid)
+		all = append(all, b)
+		return nil
+	}, ErrLogger(eh().ErrorHandler))
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	assert.Equal(t, fids[0], allFids[2])
+	assert.Nil(t, all[1])
+	assert.Equal(t, "01010000a0e6100000", hex.EncodeToString(all[0][0:9]))
+	g5, _ := NewGeometryFromEWKB(all[2])
+	wkt, _ = g5.WKT()
+	assert.Equal(t, "POINT Z (4 5 6)", wkt)
+	g5.Close()
+	assert.Nil(t, lyr.NextFeature())
+
+	calls := 0
+	err = lyr.ForEachEWKB(func(fid int64, b []byte) error {
+		calls++
+		return fmt.Errorf("stop")
+	})
+	assert.EqualError(t, err, "stop")
+	assert.Equal(t, 1, calls)
+}
+
+func TestGeom(t *testing.T) {
+	wgs84, _ := NewSpatialRefFromEPSG(4326)
+	defer wgs84.Close()
+	g, _ := NewGeometryFromWKT("POLYGON Z ((0 0 1,1 0 2,1 1 3,0 0 1))", wgs84)
+	defer g.Close()
+	gg, err := g.Geom()
+	require.NoError(t, err)
+	assert.Equal(t, &geom.Polygon{
+		Header: geom.Header{Layout: geom.XYZ, SRID: 4326},
+		Rings:  [][]float64{{0, 0, 1, 1, 0, 2, 1, 1, 3, 0, 0, 1}},
+	}, gg)
+	g2, err := NewGeometryFromGeom(gg, ErrLogger(eh().ErrorHandler))
+	require.NoError(t, err)
+	wkt, _ := g2.WKT()
+	assert.Equal(t, "POLYGON Z ((0 0 1,1 0 2,1 1 3,0 0 1))", wkt)
+	assert.True(t, g2.SpatialRef().IsSame(wgs84))
+	g2.Close()
+
+	curve, _ := NewGeometryFromWKT("CIRCULARSTRING (0 0,1 1,2 0)", nil)
+	defer curve.Close()
+	_, err = curve.Geom(ErrLogger(eh().ErrorHandler))
 	assert.Error(t, err)
-	_, err = ds.Bounds()
+	_, err = NewGeometryFromGeom(nil)
 	assert.Error(t, err)
-	ngt := [6]float64{0, 2, 1, 0, 1, 1}
 
-	err = ds.SetGeoTransform(ngt)
-	assert.NoError(t, err)
-	ehc = eh()
-	err = ds.SetGeoTransform(ngt, ErrLogger(ehc.ErrorHandler))
-	assert.NoError(t, err)
+	ds, _ := CreateVector(Memory, "")
+	defer ds.Close()
+	lyr, err := ds.CreateLayer("l", wgs84, GTUnknown)
+	require.NoError(t, err)
+	ls := &geom.LineString{Header: geom.Header{Layout: geom.XYM}, Coords: []float64{0, 0, 1, 1, 1, 2}}
+	fids, err := lyr.NewFeaturesFromGeoms([]geom.Geometry{ls, nil}, ErrLogger(eh().ErrorHandler))
+	require.NoError(t, err)
+	require.Len(t, fids, 2)
+	_, err = lyr.NewFeaturesFromGeoms([]geom.Geometry{&geom.Point{Header: geom.Header{SRID: 3857}, Coords: []float64{0, 0}}})
+	assert.Error(t, err)
 
-	gt, err := ds.GeoTransform()
-	assert.NoError(t, err)
-	assert.Equal(t, gt, ngt)
-	ehc = eh()
-	_, err = ds.GeoTransform(ErrLogger(ehc.ErrorHandler))
-	assert.NoError(t, err)
+	geoms := map[int64]geom.Geometry{}
+	err = lyr.ForEachGeom(func(fid int64, g geom.Geometry) error {
+		geoms[fid] = g
+		return nil
+	}, ErrLogger(eh().ErrorHandler))
+	require.NoError(t, err)
+	require.Len(t, geoms, 2)
+	ls.SetSRID(4326)
+	assert.Equal(t, ls, geoms[fids[0]])
+	assert.Nil(t, geoms[fids[1]])
 }
 
-func TestGeometryTransform(t *testing.T) {
-	sr, _ := NewSpatialRefFromEPSG(4326)
-	srm, _ := NewSpatialRefFromEPSG(3857)
-	gp, _ := NewGeometryFromWKT("POINT (10 10)", sr)
-	assert.True(t, gp.SpatialRef().IsSame(sr))
+func TestCreateCopy(t *testing.T) {
+	_ = RegisterRaster(PNG)
+	ds, _ := Create(Memory, "", 3, Byte, 16, 16)
+	defer ds.Close()
+	_ = ds.Write(0, 0, make([]byte, 16*16*3), 16, 16)
+
+	_, err := Create(PNG, "/vsimem/createcopy.png", 3, Byte, 16, 16)
+	assert.Contains(t, err.Error(), "only supports CreateCopy")
+
+	calls := 0
+	cp, err := ds.CreateCopy(PNG, "/vsimem/createcopy.png",
+		CreationOption("ZLEVEL=9"), ConfigOption("GDAL_PAM_ENABLED=NO"),
+		Progress(func(complete float64, msg string) bool {
+			calls++
+			return true
+		}))
+	require.NoError(t, err)
+	assert.Greater(t, calls, 0)
+	assert.Equal(t, 3, len(cp.Bands()))
+	_ = cp.Close()
+	_ = VSIUnlink("/vsimem/createcopy.png")
 
-	err := gp.Reproject(srm)
-	assert.NoError(t, err)
-	assert.True(t, gp.SpatialRef().IsSame(srm))
-	gp.Close()
+	_, err = ds.CreateCopy(PNG, "/vsimem/createcopy.png",
+		Progress(func(complete float64, msg string) bool {
+			return false
+		}))
+	assert.Error(t, err)
+	_ = VSIUnlink("/vsimem/createcopy.png")
 
+	fds, _ := Create(Memory, "", 1, Float32, 16, 16)
+	defer fds.Close()
 	ehc := eh()
-	gp, _ = NewGeometryFromWKT("POINT (10 10)", sr)
-	err = gp.Reproject(srm, ErrLogger(ehc.ErrorHandler))
-	assert.NoError(t, err)
-
-	nwkt, _ := gp.WKT()
-	assert.NotEqual(t, "POINT (10 10)", nwkt)
-	gp.SetSpatialRef(sr)
-	assert.True(t, gp.SpatialRef().IsSame(sr))
+	_, err = fds.CreateCopy(PNG, "/vsimem/createcopy.png", Strict(), ErrLogger(ehc.ErrorHandler))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Float32")
+	assert.NotContains(t, err.Error(), "only supports")
+	assert.NotEqual(t, 0, ehc.errs)
 
-	gp.Close()
+	tmpname := tempfile()
+	defer os.Remove(tmpname)
+	cp, err = ds.CreateCopy(GTiff, tmpname, CreationOption("TILED=YES", "BLOCKXSIZE=16", "BLOCKYSIZE=16"))
+	require.NoError(t, err)
+	assert.Equal(t, 16, cp.Structure().BlockSizeX)
+	_ = cp.Close()
 
-	gp, _ = NewGeometryFromWKT("POINT (10 91)", sr)
-	err = gp.Reproject(srm)
+	_, err = ds.CreateCopy("bogus", "/vsimem/bogus")
 	assert.Error(t, err)
-	gp.Close()
+}
 
-	ehc = eh()
-	gp, _ = NewGeometryFromWKT("POINT (10 91)", sr, ErrLogger(ehc.ErrorHandler))
-	err = gp.Reproject(srm)
-	assert.Error(t, err)
-	gp.Close()
+func TestDatasetFiles(t *testing.T) {
+	tmpname := tempfile()
+	defer os.Remove(tmpname)
+	defer os.Remove(tmpname + ".msk")
+	ds, err := Create(GTiff, tmpname, 1, Byte, 16, 16)
+	require.NoError(t, err)
+	_, err = ds.CreateMaskBand(MaskPerDataset, ConfigOption("GDAL_TIFF_INTERNAL_MASK=NO"))
+	require.NoError(t, err)
+	_ = ds.Close()
 
-	trn, _ := NewTransform(sr, srm)
-	gp, _ = NewGeometryFromWKT("POINT (10 10)", nil)
+	ds, _ = Open(tmpname)
+	assert.Equal(t, []string{tmpname, tmpname + ".msk"}, ds.FileList())
+	_ = ds.Close()
+	mds, _ := Create(Memory, "", 1, Byte, 16, 16)
+	assert.Equal(t, []string{}, mds.FileList())
+	_ = mds.Close()
 
-	err = gp.Transform(trn)
+	cpname := tmpname + "-copy.tif"
+	err = CopyDatasetFiles(tmpname, cpname)
+	require.NoError(t, err)
+	_, err = os.Stat(cpname + ".msk")
+	assert.NoError(t, err)
+	_, err = os.Stat(tmpname + ".msk")
 	assert.NoError(t, err)
-	assert.True(t, gp.SpatialRef().IsSame(srm))
-	nwkt, _ = gp.WKT()
-	assert.NotEqual(t, "POINT (10 10)", nwkt)
-	gp.Close()
 
-	ehc = eh()
-	gp, _ = NewGeometryFromWKT("POINT (10 10)", nil)
-	err = gp.Transform(trn, ErrLogger(ehc.ErrorHandler))
+	mvname := tmpname + "-moved.tif"
+	err = RenameDataset(cpname, mvname, GTiff)
+	require.NoError(t, err)
+	_, err = os.Stat(cpname + ".msk")
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(mvname + ".msk")
 	assert.NoError(t, err)
-	gp.Close()
 
-	gp, _ = NewGeometryFromWKT("POINT (10 91)", sr)
-	err = gp.Transform(trn)
-	assert.Error(t, err)
-	gp.Close()
+	ehc := eh()
+	err = DeleteDataset(mvname, ErrLogger(ehc.ErrorHandler))
+	require.NoError(t, err)
+	_, err = os.Stat(mvname)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(mvname + ".msk")
+	assert.True(t, os.IsNotExist(err))
 
-	ehc = eh()
-	gp, _ = NewGeometryFromWKT("POINT (10 91)", sr)
-	err = gp.Transform(trn, ErrLogger(ehc.ErrorHandler))
+	err = DeleteDataset(mvname)
 	assert.Error(t, err)
-	gp.Close()
-}
-
-func TestProjBounds(t *testing.T) {
-	sr4326, _ := NewSpatialRefFromEPSG(4326)
-	sr3857, _ := NewSpatialRefFromEPSG(3857)
-	box, err := NewGeometryFromWKT("POLYGON((-180 -91,-180 90,180 90,180 -90,-180 -91))", sr4326)
-	assert.NoError(t, err)
-	_, err = box.Bounds(sr3857)
+	err = DeleteDataset(tmpname, DriverName("bogus"))
 	assert.Error(t, err)
-	_, err = box.Bounds(&SpatialRef{handle: nil})
+	err = RenameDataset(mvname, cpname)
+	assert.Error(t, err)
+	err = CopyDatasetFiles(mvname, cpname, ConfigOption("CPL_DEBUG=OFF"))
 	assert.Error(t, err)
-
 }
 
 func TestTranslate(t *testing.T) {
@@ -4900,6 +6428,80 @@ func TestGCPsToGeoTransform(t *testing.T) {
 	assert.Equal(t, 0.2, geoTransform[5])
 }
 
+func TestTransformer(t *testing.T) {
+	ds, _ := Create(Memory, "", 1, Byte, 100, 100)
+	defer ds.Close()
+	_ = ds.SetGeoTransform([6]float64{10, 0.5, 0, 20, 0, -0.5})
+	sr, _ := NewSpatialRefFromEPSG(4326)
+	defer sr.Close()
+	_ = ds.SetSpatialRef(sr)
+
+	ehc := eh()
+	tr, err := NewTransformer(ds, nil, ErrLogger(ehc.ErrorHandler))
+	assert.NoError(t, err)
+	x := []float64{0, 10, 100}
+	y := []float64{0, 20, 100}
+	oks := make([]bool, 3)
+	err = tr.TransformPoints(x, y, nil, oks)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{10, 15, 60}, x)
+	assert.Equal(t, []float64{20, 10, -30}, y)
+	assert.Equal(t, []bool{true, true, true}, oks)
+	err = tr.InverseTransformPoints(x, y, nil, nil)
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{0, 10, 100}, x, 1e-9)
+	assert.InDeltaSlice(t, []float64{0, 20, 100}, y, 1e-9)
+	err = tr.TransformPoints(x, y[0:1], nil, nil)
+	assert.Error(t, err)
+	tr.Close()
+	tr.Close() //double close is a noop
+
+	tr, err = NewTransformer(ds, nil, TransformerOption("DST_SRS=EPSG:3857"))
+	assert.NoError(t, err)
+	x, y = []float64{0}, []float64{0}
+	z := []float64{0}
+	err = tr.TransformPoints(x, y, z, nil)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1113194.9, x[0], 0.1)
+	tr.Close()
+
+	gcpds, _ := Create(Memory, "", 1, Byte, 100, 100)
+	defer gcpds.Close()
+	gcps := []GCP{
+		{DfGCPPixel: 0, DfGCPLine: 0, DfGCPX: 10, DfGCPY: 20},
+		{DfGCPPixel: 100, DfGCPLine: 0, DfGCPX: 60, DfGCPY: 20},
+		{DfGCPPixel: 100, DfGCPLine: 100, DfGCPX: 60, DfGCPY: -30},
+		{DfGCPPixel: 0, DfGCPLine: 100, DfGCPX: 10, DfGCPY: -30},
+		{DfGCPPixel: 50, DfGCPLine: 50, DfGCPX: 35, DfGCPY: -5},
+	}
+	err = gcpds.SetGCPs(gcps, GCPSpatialRef(sr))
+	assert.NoError(t, err)
+	for _, method := range []string{"GCP_POLYNOMIAL", "GCP_TPS"} {
+		tr, err = NewTransformer(gcpds, nil, TransformerOption("METHOD="+method))
+		if !assert.NoError(t, err, method) {
+			continue
+		}
+		x, y = []float64{20, 50}, []float64{80, 50}
+		err = tr.TransformPoints(x, y, nil, nil)
+		assert.NoError(t, err, method)
+		assert.InDeltaSlice(t, []float64{20, 35}, x, 1e-6, method)
+		assert.InDeltaSlice(t, []float64{-20, -5}, y, 1e-6, method)
+		err = tr.InverseTransformPoints(x, y, nil, nil)
+		assert.NoError(t, err, method)
+		assert.InDeltaSlice(t, []float64{20, 50}, x, 1e-3, method)
+		assert.InDeltaSlice(t, []float64{80, 50}, y, 1e-3, method)
+		tr.Close()
+	}
+
+	_, err = NewTransformer(ds, nil, TransformerOption("METHOD=RPC"))
+	assert.Error(t, err)
+	ehc = eh()
+	_, err = NewTransformer(ds, nil, TransformerOption("METHOD=RPC"), ErrLogger(ehc.ErrorHandler))
+	assert.Error(t, err)
+	_, err = NewTransformer(ds, nil, TransformerOption("DST_SRS=bogus"))
+	assert.Error(t, err)
+}
+
 func TestDemHillshade(t *testing.T) {
 	// 1. Create an image, linearly interpolated, from dark (on the left) to white (on the right), using `Grid()`
 	var (
@@ -5181,3 +6783,294 @@ func TestDemColorReliefInvalidFilename(t *testing.T) {
 	_, err = vrtDs.Dem("/vsimem/out.tiff", "color-relief", invalidColorReliefFilename, []string{})
 	assert.Error(t, err)
 }
+
+func TestCalcExpression(t *testing.T) {
+	for expr, expected := range map[string]float64{
+		"(B4-B3)/(B4+B3)":           0.5,
+		"-B3**2":                    -4,
+		"2^3^2":                     512,
+		"B4 > B3 ? B4 : B3":         6,
+		"where(B3 >= 3, 1, 0)":      0,
+		"max(B3, B4, 10) + abs(-1)": 11,
+		"!(B3 == 2) || B4 % 4 == 2": 1,
+		"1e1 * .5":                  5,
+	} {
+		e, err := ParseExpression(expr)
+		require.NoError(t, err, expr)
+		val, err := e.Eval(map[string]float64{"B3": 2, "B4": 6})
+		require.NoError(t, err, expr)
+		assert.Equal(t, expected, val, expr)
+	}
+	e, _ := ParseExpression("(B4-B3)/(B4+B3)")
+	assert.Equal(t, []string{"B4", "B3"}, e.Variables())
+	assert.Equal(t, "(B4-B3)/(B4+B3)", e.String())
+	_, err := e.Eval(map[string]float64{"B4": 1})
+	assert.Error(t, err)
+
+	for _, expr := range []string{"", "A+", "(A", "A $ B", "foo(A)", "where(A,B)", "A ? B", "1.2.3"} {
+		_, err := ParseExpression(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestCalc(t *testing.T) {
+	ds1, _ := Create(Memory, "", 1, Byte, 4, 4)
+	defer ds1.Close()
+	ds2, _ := Create(Memory, "", 1, Int16, 4, 4)
+	defer ds2.Close()
+	_ = ds1.SetGeoTransform([6]float64{10, 1, 0, 20, 0, -1})
+	_ = ds1.Bands()[0].SetNoData(0)
+	b1 := make([]byte, 16)
+	b2 := make([]int16, 16)
+	for i := range b1 {
+		b1[i] = byte(i)
+		b2[i] = int16(2 * i)
+	}
+	_ = ds1.Write(0, 0, b1, 4, 4)
+	_ = ds2.Write(0, 0, b2, 4, 4)
+
+	out, err := Calc("", "(A+B)/2", map[string]Band{"A": ds1.Bands()[0], "B": ds2.Bands()[0]},
+		Memory, OutputType(Float64), BlockSize(3, 3))
+	require.NoError(t, err)
+	defer out.Close()
+	st := out.Structure()
+	assert.Equal(t, Float64, st.DataType)
+	assert.Equal(t, 4, st.SizeX)
+	gt, _ := out.GeoTransform()
+	assert.Equal(t, [6]float64{10, 1, 0, 20, 0, -1}, gt)
+	nd, ok := out.Bands()[0].NoData()
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, nd)
+	res := make([]float64, 16)
+	_ = out.Read(0, 0, res, 4, 4)
+	assert.Equal(t, 0.0, res[0])
+	for i := 1; i < 16; i++ {
+		assert.Equal(t, 1.5*float64(i), res[i])
+	}
+
+	out2, err := Calc("", "B > 10 ? 1 : 0", map[string]Band{"B": ds2.Bands()[0]},
+		Memory, OutputType(Byte), OutputNoData(255), CreationOption("INTERLEAVE=BAND"))
+	require.NoError(t, err)
+	defer out2.Close()
+	nd, _ = out2.Bands()[0].NoData()
+	assert.Equal(t, 255.0, nd)
+	bres := make([]byte, 16)
+	_ = out2.Read(0, 0, bres, 4, 4)
+	assert.Equal(t, byte(0), bres[5])
+	assert.Equal(t, byte(1), bres[6])
+
+	_, err = Calc("", "A+C", map[string]Band{"A": ds1.Bands()[0]}, Memory)
+	assert.Error(t, err)
+	_, err = Calc("", "A+", map[string]Band{"A": ds1.Bands()[0]}, Memory)
+	assert.Error(t, err)
+	_, err = Calc("", "1+1", map[string]Band{"A": ds1.Bands()[0]}, Memory)
+	assert.Error(t, err)
+	ds3, _ := Create(Memory, "", 1, Byte, 5, 4)
+	defer ds3.Close()
+	_, err = Calc("", "A+B", map[string]Band{"A": ds1.Bands()[0], "B": ds3.Bands()[0]}, Memory)
+	assert.Error(t, err)
+	ehc := eh()
+	_, err = Calc("", "A", map[string]Band{"A": ds1.Bands()[0]}, Memory, OutputType(Byte),
+		CreationOption("FOO=BAR"), ErrLogger(ehc.ErrorHandler))
+	assert.Error(t, err)
+	assert.Equal(t, 1, ehc.errs)
+	_, err = Calc("", "A", map[string]Band{"A": ds1.Bands()[0]}, VRT)
+	assert.Error(t, err) //in-memory inputs cannot be referenced by a vrt
+
+	//created directly with the target driver
+	outt, err := Calc("/vsimem/calc.tif", "B > 10 ? 1 : 0", map[string]Band{"B": ds2.Bands()[0]},
+		GTiff, OutputType(Byte), BlockSize(2, 2))
+	require.NoError(t, err)
+	defer func() { _ = VSIUnlink("/vsimem/calc.tif") }()
+	assert.Equal(t, "GTiff", outt.Driver().ShortName())
+	_ = outt.Read(0, 0, bres, 4, 4)
+	assert.Equal(t, byte(1), bres[6])
+	_ = outt.Close()
+
+	//createcopy only driver
+	out3, err := Calc("/vsimem/calc.png", "B > 10 ? 1 : 0", map[string]Band{"B": ds2.Bands()[0]},
+		PNG, OutputType(Byte))
+	require.NoError(t, err)
+	defer func() { _ = VSIUnlink("/vsimem/calc.png") }()
+	assert.Equal(t, "PNG", out3.Driver().ShortName())
+	_ = out3.Read(0, 0, bres, 4, 4)
+	assert.Equal(t, byte(0), bres[5])
+	assert.Equal(t, byte(1), bres[6])
+	_ = out3.Close()
+
+	//georeferencing mismatch
+	ds4, _ := Create(Memory, "", 1, Byte, 4, 4)
+	defer ds4.Close()
+	_ = ds4.SetGeoTransform([6]float64{11, 1, 0, 20, 0, -1})
+	_, err = Calc("", "A+B", map[string]Band{"A": ds1.Bands()[0], "B": ds4.Bands()[0]}, Memory)
+	assert.Error(t, err)
+	sr1, _ := NewSpatialRefFromEPSG(4326)
+	defer sr1.Close()
+	sr2, _ := NewSpatialRefFromEPSG(32631)
+	defer sr2.Close()
+	_ = ds4.SetGeoTransform([6]float64{10, 1, 0, 20, 0, -1})
+	_ = ds1.SetSpatialRef(sr1)
+	_ = ds4.SetSpatialRef(sr2)
+	_, err = Calc("", "A+B", map[string]Band{"A": ds1.Bands()[0], "B": ds4.Bands()[0]}, Memory)
+	assert.Error(t, err)
+	_ = ds4.SetSpatialRef(sr1)
+	out4, err := Calc("", "A+B", map[string]Band{"A": ds1.Bands()[0], "B": ds4.Bands()[0]}, Memory)
+	require.NoError(t, err)
+	_ = out4.Close()
+}
+
+func TestCalcVRT(t *testing.T) {
+	if Version().Major() < 3 || (Version().Major() == 3 && Version().Minor() < 4) {
+		t.Skip("derived bands with arguments require gdal >= 3.4")
+	}
+	ds, _ := Create(GTiff, "/vsimem/calc.tif", 2, Float32, 8, 8)
+	defer func() { _ = VSIUnlink("/vsimem/calc.tif") }()
+	_ = ds.Bands()[0].SetNoData(-1)
+	buf := make([]float32, 128)
+	for i := range buf {
+		buf[i] = float32(i % 64)
+	}
+	buf[0] = -1
+	_ = ds.Write(0, 0, buf, 8, 8, BandInterleaved())
+	_ = ds.Close()
+	ds, _ = Open("/vsimem/calc.tif")
+	defer ds.Close()
+	bands := ds.Bands()
+
+	vrt, err := Calc("/vsimem/calc.vrt", "NIR*2 - RED", map[string]Band{"RED": bands[0], "NIR": bands[1]},
+		VRT, OutputType(Float64))
+	require.NoError(t, err)
+	defer func() { _ = VSIUnlink("/vsimem/calc.vrt") }()
+	defer vrt.Close()
+	assert.Equal(t, "VRT", vrt.Driver().ShortName())
+	nd, ok := vrt.Bands()[0].NoData()
+	assert.True(t, ok)
+	assert.Equal(t, -1.0, nd)
+	res := make([]float64, 64)
+	err = vrt.Read(0, 0, res, 8, 8)
+	require.NoError(t, err)
+	assert.Equal(t, -1.0, res[0])
+	for i := 1; i < 64; i++ {
+		assert.Equal(t, float64(i), res[i])
+	}
+}
+
+func TestAdviseRead(t *testing.T) {
+	ds, _ := Open("testdata/test.tif")
+	defer ds.Close()
+	assert.NoError(t, ds.AdviseRead(0, 0, 5, 5))
+	assert.NoError(t, ds.AdviseRead(0, 0, 5, 5, Window(10, 10), Bands(1)))
+	assert.NoError(t, ds.Bands()[0].AdviseRead(2, 2, 4, 4, ConfigOption("GDAL_CACHEMAX=10")))
+	ehc := eh()
+	err := ds.AdviseRead(8, 8, 5, 5, ErrLogger(ehc.ErrorHandler))
+	assert.Error(t, err)
+	assert.Equal(t, 1, ehc.errs)
+	err = ds.Bands()[0].AdviseRead(0, 0, 20, 20)
+	assert.Error(t, err)
+}
+
+func TestPrefetch(t *testing.T) {
+	vpa := vpHandler{datas: make(map[string]KeySizerReaderAt)}
+	tifdat, _ := ioutil.ReadFile("testdata/test.tif")
+	vpa.datas["test.tif"] = mbufHandler{tifdat}
+	_ = RegisterVSIHandler("testprefetch://", vpa, VSIHandlerStripPrefix(true))
+	ds, err := Open("testprefetch://test.tif")
+	require.NoError(t, err)
+	defer ds.Close()
+
+	windows := []Block{
+		{X0: 0, Y0: 0, W: 5, H: 5},
+		{X0: 5, Y0: 0, W: 5, H: 5},
+		{X0: 0, Y0: 5, W: 10, H: 5},
+		{X0: 8, Y0: 8, W: 5, H: 5},
+	}
+	expected := make([][]byte, 3)
+	for i, w := range windows[:3] {
+		expected[i] = make([]byte, w.W*w.H*3)
+		_ = ds.Read(w.X0, w.Y0, expected[i], w.W, w.H)
+	}
+	results, stop := ds.Prefetch(windows, PrefetchDepth(2))
+	defer stop()
+	i := 0
+	for res := range results {
+		assert.Equal(t, windows[i], res.Block)
+		if i < 3 {
+			assert.NoError(t, res.Err)
+			assert.Equal(t, expected[i], res.Data)
+		} else {
+			assert.Error(t, res.Err) //out of bounds window
+		}
+		i++
+	}
+	assert.Equal(t, len(windows), i)
+
+	results, stop = ds.Prefetch(windows[:1], Bands(1), OutputType(Float32))
+	res := <-results
+	stop()
+	assert.NoError(t, res.Err)
+	fdata := res.Data.([]float32)
+	assert.Len(t, fdata, 25)
+	assert.Equal(t, float32(expected[0][1]), fdata[0])
+
+	results, stop = ds.Prefetch(windows)
+	<-results
+	stop()
+	for range results {
+	}
+
+	//concurrent reads on reopened handles
+	cnt := &countingHandler{mbufHandler: mbufHandler{tifdat}}
+	vpc := mvpHandler{vpHandler{datas: map[string]KeySizerReaderAt{"test.tif": cnt}}}
+	_ = RegisterVSIHandler("testprefetchc://", vpc, VSIHandlerStripPrefix(true),
+		VSIHandlerBufferSize(0), VSIHandlerCacheSize(0))
+	cds, err := Open("testprefetchc://test.tif")
+	require.NoError(t, err)
+	defer cds.Close()
+	cwindows := []Block{}
+	cexpected := [][]byte{}
+	for y := 0; y < 10; y++ {
+		cwindows = append(cwindows, Block{X0: 0, Y0: y, W: 10, H: 1})
+		cexpected = append(cexpected, make([]byte, 30))
+		require.NoError(t, cds.Read(0, y, cexpected[y], 10, 1))
+	}
+	atomic.StoreInt32(&cnt.max, 0)
+	results, stop = cds.Prefetch(cwindows, PrefetchDepth(4))
+	defer stop()
+	i = 0
+	for res := range results {
+		assert.Equal(t, cwindows[i], res.Block)
+		require.NoError(t, res.Err)
+		assert.Equal(t, cexpected[i], res.Data)
+		i++
+	}
+	assert.Equal(t, len(cwindows), i)
+	assert.Greater(t, atomic.LoadInt32(&cnt.max), int32(1))
+}
+
+// countingHandler records the maximum number of concurrent reads
+type countingHandler struct {
+	mbufHandler
+	cur, max int32
+}
+
+func (ch *countingHandler) track() func() {
+	n := atomic.AddInt32(&ch.cur, 1)
+	for {
+		m := atomic.LoadInt32(&ch.max)
+		if n <= m || atomic.CompareAndSwapInt32(&ch.max, m, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	return func() { atomic.AddInt32(&ch.cur, -1) }
+}
+
+func (ch *countingHandler) ReadAt(key string, buf []byte, off int64) (int, error) {
+	defer ch.track()()
+	return ch.mbufHandler.ReadAt(key, buf, off)
+}
+
+func (ch *countingHandler) ReadAtMulti(key string, bufs [][]byte, offs []int64) ([]int, error) {
+	defer ch.track()()
+	return ch.mbufHandler.ReadAtMulti(key, bufs, offs)
+}
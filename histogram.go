@@ -86,6 +86,7 @@ func (aoo approximateOkOption) setHistogramOpt(ho *histogramOpts) {
 func Approximate() interface {
 	HistogramOption
 	StatisticsOption
+	ComputeMinMaxOption
 } {
 	return approximateOkOption{}
 }
@@ -60,6 +60,72 @@ type setSpatialRefOpts struct {
 	errorHandler ErrorHandler
 }
 
+// SetCoordinateEpochOption is an option that can be passed to SpatialRef.SetCoordinateEpoch()
+//
+// Available SetCoordinateEpochOptions are:
+//   - ErrLogger
+type SetCoordinateEpochOption interface {
+	setSetCoordinateEpochOpt(o *setCoordinateEpochOpts)
+}
+type setCoordinateEpochOpts struct {
+	errorHandler ErrorHandler
+}
+
+// PromoteTo3DOption is an option that can be passed to SpatialRef.PromoteTo3D()
+//
+// Available PromoteTo3DOptions are:
+//   - ErrLogger
+type PromoteTo3DOption interface {
+	setPromoteTo3DOpt(o *promoteTo3DOpts)
+}
+type promoteTo3DOpts struct {
+	errorHandler ErrorHandler
+}
+
+// DemoteTo2DOption is an option that can be passed to SpatialRef.DemoteTo2D()
+//
+// Available DemoteTo2DOptions are:
+//   - ErrLogger
+type DemoteTo2DOption interface {
+	setDemoteTo2DOpt(o *demoteTo2DOpts)
+}
+type demoteTo2DOpts struct {
+	errorHandler ErrorHandler
+}
+
+// CloneGeogCSOption is an option that can be passed to SpatialRef.CloneGeogCS()
+//
+// Available CloneGeogCSOptions are:
+//   - ErrLogger
+type CloneGeogCSOption interface {
+	setCloneGeogCSOpt(o *cloneGeogCSOpts)
+}
+type cloneGeogCSOpts struct {
+	errorHandler ErrorHandler
+}
+
+// StripVerticalOption is an option that can be passed to SpatialRef.StripVertical()
+//
+// Available StripVerticalOptions are:
+//   - ErrLogger
+type StripVerticalOption interface {
+	setStripVerticalOpt(o *stripVerticalOpts)
+}
+type stripVerticalOpts struct {
+	errorHandler ErrorHandler
+}
+
+// CloneTransformOption is an option that can be passed to Transform.Clone()
+//
+// Available CloneTransformOptions are:
+//   - ErrLogger
+type CloneTransformOption interface {
+	setCloneTransformOpt(o *cloneTransformOpts)
+}
+type cloneTransformOpts struct {
+	errorHandler ErrorHandler
+}
+
 // SetNoDataOption is an option that can be passed to Band.SetNodata(),
 // Band.ClearNodata(), Dataset.SetNodata()
 //
@@ -84,6 +150,50 @@ type setScaleOffsetOpts struct {
 	errorHandler ErrorHandler
 }
 
+// AttributeTableOption is an option that can be passed to Band.AttributeTable()
+//
+// Available AttributeTableOptions are:
+//   - ErrLogger
+type AttributeTableOption interface {
+	setAttributeTableOpt(ato *attributeTableOpts)
+}
+type attributeTableOpts struct {
+	errorHandler ErrorHandler
+}
+
+// SetAttributeTableOption is an option that can be passed to Band.SetAttributeTable()
+//
+// Available SetAttributeTableOptions are:
+//   - ErrLogger
+type SetAttributeTableOption interface {
+	setSetAttributeTableOpt(sato *setAttributeTableOpts)
+}
+type setAttributeTableOpts struct {
+	errorHandler ErrorHandler
+}
+
+// SetCategoryNamesOption is an option that can be passed to Band.SetCategoryNames()
+//
+// Available SetCategoryNamesOptions are:
+//   - ErrLogger
+type SetCategoryNamesOption interface {
+	setSetCategoryNamesOpt(scno *setCategoryNamesOpts)
+}
+type setCategoryNamesOpts struct {
+	errorHandler ErrorHandler
+}
+
+// SetUnitOption is an option that can be passed to Band.SetUnit()
+//
+// Available SetUnitOptions are:
+//   - ErrLogger
+type SetUnitOption interface {
+	setSetUnitOpt(so *setUnitOpts)
+}
+type setUnitOpts struct {
+	errorHandler ErrorHandler
+}
+
 // SetColorInterpOption is an option that can be passed to Band.SetColorInterpretation()
 //
 // Available SetColorInterpOption are:
@@ -138,6 +248,10 @@ type bandIOOpts struct {
 	resampling                ResamplingAlg
 	pixelSpacing, lineSpacing int
 	pixelStride, lineStride   int
+	mask                      []byte
+	scaleOffset               bool
+	complexValues             bool
+	complexPart               ComplexPart
 	errorHandler              ErrorHandler
 }
 
@@ -151,6 +265,10 @@ type bandIOOpts struct {
 //   - ConfigOption
 //   - PixelSpacing
 //   - LineSpacing
+//   - ValidityMask
+//   - ApplyScaleOffset
+//   - ComplexValues
+//   - ErrLogger
 type BandIOOption interface {
 	setBandIOOpt(ro *bandIOOpts)
 }
@@ -311,6 +429,8 @@ type datasetIOOpts struct {
 	bandInterleave                         bool //return r1r2...rn,g1g2...gn,b1b2...bn instead of r1g1b1,r2g2b2,...,rngnbn
 	bandSpacing, pixelSpacing, lineSpacing int
 	bandStride, pixelStride, lineStride    int
+	mask                                   []byte
+	scaleOffset                            bool
 	errorHandler                           ErrorHandler
 }
 
@@ -328,10 +448,41 @@ type datasetIOOpts struct {
 //   - PixelSpacing
 //   - LineSpacing
 //   - BandSpacing
+//   - ValidityMask
+//   - ApplyScaleOffset
+//   - ErrLogger
 type DatasetIOOption interface {
 	setDatasetIOOpt(ro *datasetIOOpts)
 }
 
+type adviseReadOpts struct {
+	config            []string
+	bands             []int
+	dsWidth, dsHeight int
+	errorHandler      ErrorHandler
+}
+
+// DatasetAdviseReadOption is an option to modify the default behavior of dataset.AdviseRead
+//
+// Available DatasetAdviseReadOptions are:
+//   - Window
+//   - Bands
+//   - ConfigOption
+//   - ErrLogger
+type DatasetAdviseReadOption interface {
+	setDatasetAdviseReadOpt(ro *adviseReadOpts)
+}
+
+// BandAdviseReadOption is an option to modify the default behavior of band.AdviseRead
+//
+// Available BandAdviseReadOptions are:
+//   - Window
+//   - ConfigOption
+//   - ErrLogger
+type BandAdviseReadOption interface {
+	setBandAdviseReadOpt(ro *adviseReadOpts)
+}
+
 type dsCreateOpts struct {
 	config       []string
 	creation     []string
@@ -348,6 +499,72 @@ type DatasetCreateOption interface {
 	setDatasetCreateOpt(dc *dsCreateOpts)
 }
 
+type validateCreationOptionsOpts struct {
+	creation     []string
+	errorHandler ErrorHandler
+}
+
+// ValidateCreationOptionsOption is an option that can be passed to Driver.ValidateCreationOptions()
+//
+// Available ValidateCreationOptionsOptions are:
+//   - CreationOption
+//   - ErrLogger
+type ValidateCreationOptionsOption interface {
+	setValidateCreationOptionsOpt(o *validateCreationOptionsOpts)
+}
+
+type datasetFilesOpts struct {
+	driver       DriverName
+	config       []string
+	errorHandler ErrorHandler
+}
+
+// DatasetFilesOption is an option that can be passed to DeleteDataset(), RenameDataset()
+// and CopyDatasetFiles()
+//
+// Available DatasetFilesOptions are:
+//   - DriverName
+//   - ConfigOption
+//   - ErrLogger
+type DatasetFilesOption interface {
+	setDatasetFilesOpt(o *datasetFilesOpts)
+}
+
+type createCopyOpts struct {
+	creation     []string
+	config       []string
+	progress     ProgressFunc
+	strict       bool
+	errorHandler ErrorHandler
+}
+
+// CreateCopyOption is an option that can be passed to Dataset.CreateCopy()
+//
+// Available CreateCopyOptions are:
+//   - CreationOption
+//   - ConfigOption
+//   - Progress
+//   - Strict
+//   - ErrLogger
+type CreateCopyOption interface {
+	setCreateCopyOpt(o *createCopyOpts)
+}
+
+type strictOpt struct{}
+
+// Strict makes Dataset.CreateCopy fail if the output format is not able to represent
+// the source dataset exactly (e.g. unsupported datatype or band count), instead of
+// doing a best effort conversion.
+func Strict() interface {
+	CreateCopyOption
+} {
+	return strictOpt{}
+}
+
+func (so strictOpt) setCreateCopyOpt(o *createCopyOpts) {
+	o.strict = true
+}
+
 type openOpts struct {
 	flags        uint
 	drivers      []string //list of drivers that can be tried to open the given name
@@ -357,7 +574,7 @@ type openOpts struct {
 	errorHandler ErrorHandler
 }
 
-// OpenOption is an option passed to Open()
+// OpenOption is an option passed to Open() or IdentifyDriver()
 //
 // Available OpenOptions are:
 //   - Drivers
@@ -547,7 +764,7 @@ type geometryWKBOpts struct {
 	errorHandler ErrorHandler
 }
 
-// GeometryWKBOption is an option passed to Geometry.WKB()
+// GeometryWKBOption is an option passed to Geometry.WKB(), Geometry.EWKB() or Geometry.Geom()
 //
 // Available options are:
 //   - ErrLogger
@@ -567,6 +784,19 @@ type NewGeometryOption interface {
 	setNewGeometryOpt(o *newGeometryOpts)
 }
 
+type layerGeometriesOpts struct {
+	errorHandler ErrorHandler
+}
+
+// LayerGeometriesOption is an option passed to Layer.ForEachEWKB(), Layer.ForEachGeom(),
+// Layer.NewFeaturesFromEWKB() and Layer.NewFeaturesFromGeoms()
+//
+// Available options are:
+//   - ErrLogger
+type LayerGeometriesOption interface {
+	setLayerGeometriesOpt(o *layerGeometriesOpts)
+}
+
 type updateFeatureOpts struct {
 	errorHandler ErrorHandler
 }
@@ -705,6 +935,8 @@ func Bands(bnds ...int) interface {
 	BuildOverviewsOption
 	RasterizeGeometryOption
 	BuildVRTOption
+	DatasetAdviseReadOption
+	PrefetchOption
 } {
 	ib := make([]int, len(bnds))
 	for i := range bnds {
@@ -725,6 +957,12 @@ func (bo bandOpt) setRasterizeGeometryOpt(o *rasterizeGeometryOpts) {
 func (bo bandOpt) setBuildVRTOpt(bvo *buildVRTOpts) {
 	bvo.bands = bo.bnds
 }
+func (bo bandOpt) setDatasetAdviseReadOpt(ro *adviseReadOpts) {
+	ro.bands = bo.bnds
+}
+func (bo bandOpt) setPrefetchOpt(po *prefetchOpts) {
+	po.bands = bo.bnds
+}
 
 type bandSpacingOpt struct {
 	sp int
@@ -846,6 +1084,8 @@ type windowOpt struct {
 func Window(sx, sy int) interface {
 	DatasetIOOption
 	BandIOOption
+	DatasetAdviseReadOption
+	BandAdviseReadOption
 } {
 	return windowOpt{sx, sy}
 }
@@ -858,6 +1098,89 @@ func (wo windowOpt) setBandIOOpt(ro *bandIOOpts) {
 	ro.dsWidth = wo.sx
 	ro.dsHeight = wo.sy
 }
+func (wo windowOpt) setDatasetAdviseReadOpt(ro *adviseReadOpts) {
+	ro.dsWidth = wo.sx
+	ro.dsHeight = wo.sy
+}
+func (wo windowOpt) setBandAdviseReadOpt(ro *adviseReadOpts) {
+	ro.dsWidth = wo.sx
+	ro.dsHeight = wo.sy
+}
+
+type validityMaskOpt struct {
+	mask []byte
+}
+
+// ValidityMask makes Read populate mask with the validity of each of the returned pixels.
+// mask must contain at least bufWidth*bufHeight items, and is populated in row-major
+// order regardless of the spacing options used for the pixel buffer.
+//
+// A mask value of 0 denotes an invalid pixel, 255 a valid one. Intermediate values
+// denote partially transparent pixels when the validity is defined by an alpha band.
+// The validity is resolved from the nodata values, alpha bands, or per-band and
+// per-dataset mask bands, as reported by Band.MaskFlags and Band.MaskBand. When reading
+// multiple bands of a dataset, the returned validity is the minimum of the validities of
+// all the bands. Additionally, NaN values read into a []float32 or []float64 buffer are
+// always flagged as invalid.
+//
+// ValidityMask cannot be used when writing.
+func ValidityMask(mask []byte) interface {
+	BandIOOption
+	DatasetIOOption
+} {
+	return validityMaskOpt{mask}
+}
+
+func (vm validityMaskOpt) setBandIOOpt(ro *bandIOOpts) {
+	ro.mask = vm.mask
+}
+func (vm validityMaskOpt) setDatasetIOOpt(ro *datasetIOOpts) {
+	ro.mask = vm.mask
+}
+
+type scaleOffsetOpt struct{}
+
+// ApplyScaleOffset makes Read return physical values, i.e. raw*Scale+Offset, using the
+// scale and offset of each band as returned by Band.Structure(). The unit of the returned
+// values is given by Band.Unit(). Pixels equal to the band's nodata value are left untouched.
+//
+// ApplyScaleOffset can only be used when reading into a []float32 or []float64 buffer.
+func ApplyScaleOffset() interface {
+	BandIOOption
+	DatasetIOOption
+} {
+	return scaleOffsetOpt{}
+}
+
+func (so scaleOffsetOpt) setBandIOOpt(ro *bandIOOpts) {
+	ro.scaleOffset = true
+}
+func (so scaleOffsetOpt) setDatasetIOOpt(ro *datasetIOOpts) {
+	ro.scaleOffset = true
+}
+
+type complexValuesOpt struct {
+	part ComplexPart
+}
+
+// ComplexValues makes Read extract the given part (e.g. Amplitude, Phase, Intensity) of
+// the complex values of a CInt16, CInt32, CFloat32 or CFloat64 band. When used in
+// conjunction with ApplyScaleOffset, the complex values are scaled and offset (i.e. the
+// scale applies to both the real and imaginary parts, the offset to the real part only)
+// before extraction. Pixels whose real part equals the band's nodata value are set to the
+// nodata value.
+//
+// ComplexValues can only be used when reading into a []float32 or []float64 buffer.
+func ComplexValues(part ComplexPart) interface {
+	BandIOOption
+} {
+	return complexValuesOpt{part}
+}
+
+func (cv complexValuesOpt) setBandIOOpt(ro *bandIOOpts) {
+	ro.complexValues = true
+	ro.complexPart = cv.part
+}
 
 type bandInterleaveOp struct{}
 
@@ -894,6 +1217,9 @@ func CreationOption(opts ...string) interface {
 	DatasetVectorTranslateOption
 	GMLExportOption
 	RasterizeOption
+	CalcOption
+	CreateCopyOption
+	ValidateCreationOptionsOption
 } {
 	return creationOpt{opts}
 }
@@ -916,6 +1242,15 @@ func (co creationOpt) setGMLExportOpt(gmlo *gmlExportOpts) {
 func (co creationOpt) setRasterizeOpt(o *rasterizeOpts) {
 	o.create = append(o.create, co.creation...)
 }
+func (co creationOpt) setValidateCreationOptionsOpt(o *validateCreationOptionsOpts) {
+	o.creation = append(o.creation, co.creation...)
+}
+func (co creationOpt) setCreateCopyOpt(o *createCopyOpts) {
+	o.creation = append(o.creation, co.creation...)
+}
+func (co creationOpt) setCalcOpt(o *calcOpts) {
+	o.creation = append(o.creation, co.creation...)
+}
 
 type configOpt struct {
 	config []string
@@ -940,11 +1275,27 @@ func ConfigOption(cfgs ...string) interface {
 	DatasetIOOption
 	BandIOOption
 	BuildVRTOption
+	CalcOption
+	DatasetAdviseReadOption
+	BandAdviseReadOption
+	PrefetchOption
+	CreateCopyOption
+	DatasetFilesOption
+	NewTransformerOption
 	errorAndLoggingOption
 } {
 	return configOpt{cfgs}
 }
 
+func (co configOpt) setNewTransformerOpt(o *newTransformerOpts) {
+	o.config = append(o.config, co.config...)
+}
+func (co configOpt) setDatasetFilesOpt(o *datasetFilesOpts) {
+	o.config = append(o.config, co.config...)
+}
+func (co configOpt) setCreateCopyOpt(o *createCopyOpts) {
+	o.config = append(o.config, co.config...)
+}
 func (co configOpt) setBuildOverviewsOpt(bo *buildOvrOpts) {
 	bo.config = append(bo.config, co.config...)
 }
@@ -987,6 +1338,18 @@ func (co configOpt) setBandIOOpt(oo *bandIOOpts) {
 func (co configOpt) setBuildVRTOpt(bvo *buildVRTOpts) {
 	bvo.config = append(bvo.config, co.config...)
 }
+func (co configOpt) setCalcOpt(o *calcOpts) {
+	o.config = append(o.config, co.config...)
+}
+func (co configOpt) setDatasetAdviseReadOpt(o *adviseReadOpts) {
+	o.config = append(o.config, co.config...)
+}
+func (co configOpt) setBandAdviseReadOpt(o *adviseReadOpts) {
+	o.config = append(o.config, co.config...)
+}
+func (co configOpt) setPrefetchOpt(o *prefetchOpts) {
+	o.config = append(o.config, co.config...)
+}
 func (co configOpt) setErrorAndLoggingOpt(elo *errorAndLoggingOpts) {
 	elo.config = append(elo.config, co.config...)
 }
@@ -1296,6 +1659,55 @@ type GCPsToGeoTransformOption interface {
 	setGCPsToGeoTransformOpts(gcpGtOpt *gcpsToGeoTransformOpts)
 }
 
+type newTransformerOpts struct {
+	options      []string
+	config       []string
+	errorHandler ErrorHandler
+}
+
+// NewTransformerOption is an option that can be passed to NewTransformer()
+//
+// Available NewTransformerOptions are:
+//   - TransformerOption
+//   - ConfigOption
+//   - ErrLogger
+type NewTransformerOption interface {
+	setNewTransformerOpt(o *newTransformerOpts)
+}
+
+type transformerOpt struct {
+	options []string
+}
+
+// TransformerOption are options to pass to NewTransformer, in the form KEY=VALUE. They
+// are the same as the ones accepted by gdalwarp's -to switch.
+//
+// Examples are: METHOD=RPC, RPC_DEM=dem.tif, RPC_HEIGHT=100, METHOD=GCP_POLYNOMIAL,
+// MAX_GCP_ORDER=2, METHOD=GCP_TPS, METHOD=GEOLOC_ARRAY, SRC_SRS=EPSG:32631,
+// DST_SRS=EPSG:4326, etc...
+func TransformerOption(opts ...string) interface {
+	NewTransformerOption
+} {
+	return transformerOpt{opts}
+}
+
+func (to transformerOpt) setNewTransformerOpt(o *newTransformerOpts) {
+	o.options = append(o.options, to.options...)
+}
+
+type transformPointsOpts struct {
+	errorHandler ErrorHandler
+}
+
+// TransformPointsOption is an option that can be passed to Transformer.TransformPoints()
+// or Transformer.InverseTransformPoints()
+//
+// Available TransformPointsOptions are:
+//   - ErrLogger
+type TransformPointsOption interface {
+	setTransformPointsOpt(o *transformPointsOpts)
+}
+
 type gcpProjStringOpt struct {
 	projString string
 }
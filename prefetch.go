@@ -0,0 +1,238 @@
+// Copyright 2021 Airbus Defence and Space
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package godal
+
+import (
+	"fmt"
+	"sync"
+)
+
+// PrefetchedWindow is a window read by Dataset.Prefetch
+type PrefetchedWindow struct {
+	Block
+	// Data contains the pixels of the window, pixel interleaved, in a slice whose type
+	// matches the requested datatype (e.g. []byte for Byte, []float32 for Float32).
+	// CInt16 and CInt32 data is returned as []complex64 and []complex128.
+	Data interface{}
+	// Err is the error that occurred while reading the window, if any
+	Err error
+}
+
+type prefetchOpts struct {
+	bands        []int
+	dtype        DataType
+	depth        int
+	config       []string
+	errorHandler ErrorHandler
+}
+
+// PrefetchOption is an option that can be passed to Dataset.Prefetch
+//
+// Available PrefetchOptions are:
+//   - Bands
+//   - OutputType, defaults to the datatype of the dataset
+//   - PrefetchDepth
+//   - ConfigOption
+//   - ErrLogger
+type PrefetchOption interface {
+	setPrefetchOpt(po *prefetchOpts)
+}
+
+type prefetchDepthOpt struct {
+	n int
+}
+
+func (pd prefetchDepthOpt) setPrefetchOpt(po *prefetchOpts) {
+	po.depth = pd.n
+}
+
+// PrefetchDepth sets the number of windows that may be read concurrently, ahead of the
+// consumer of Dataset.Prefetch. Defaults to 1. Each additional concurrent read uses its
+// own handle on the dataset.
+func PrefetchDepth(n int) interface {
+	PrefetchOption
+} {
+	return prefetchDepthOpt{n}
+}
+
+// newBuffer allocates a buffer that can hold n items of type dtype
+func newBuffer(dtype DataType, n int) (interface{}, error) {
+	switch dtype {
+	case Byte:
+		return make([]byte, n), nil
+	case Int16:
+		return make([]int16, n), nil
+	case UInt16:
+		return make([]uint16, n), nil
+	case Int32:
+		return make([]int32, n), nil
+	case UInt32:
+		return make([]uint32, n), nil
+	case Float32:
+		return make([]float32, n), nil
+	case Float64:
+		return make([]float64, n), nil
+	case Int64:
+		return make([]int64, n), nil
+	case UInt64:
+		return make([]uint64, n), nil
+	case CInt16, CFloat32:
+		return make([]complex64, n), nil
+	case CInt32, CFloat64:
+		return make([]complex128, n), nil
+	default:
+		return nil, fmt.Errorf("unsupported datatype %d", dtype)
+	}
+}
+
+// Prefetch reads the given windows in the background and returns them in order through
+// the returned channel, which is closed once all windows have been sent.
+//
+// Up to PrefetchDepth windows are read concurrently, ahead of the consumer. As a dataset
+// handle cannot be used by several threads at once, the additional windows are read
+// through handles obtained by reopening the dataset by name (with the same ConfigOptions).
+// If the dataset cannot be reopened (e.g. for in-memory datasets), the windows are read
+// one after the other.
+//
+// Before each window is read, the driver is hinted with Dataset.AdviseRead so that the
+// drivers supporting it (e.g. GTiff) issue the ranged reads of all the blocks covering
+// the window at once. When the dataset is accessed through a handler registered with
+// RegisterVSIHandler, these reads are forwarded concurrently to the KeyMultiReader
+// implementation if available.
+//
+// The returned stop function must be called if the caller stops consuming the channel
+// before it is closed, in which case the channel is closed as soon as the pending reads
+// complete. The dataset must not be used by other goroutines until the channel has
+// been closed.
+func (ds *Dataset) Prefetch(windows []Block, opts ...PrefetchOption) (<-chan PrefetchedWindow, func()) {
+	po := prefetchOpts{depth: 1}
+	for _, opt := range opts {
+		opt.setPrefetchOpt(&po)
+	}
+	if po.depth < 1 {
+		po.depth = 1
+	}
+	st := ds.Structure()
+	if po.dtype == Unknown {
+		po.dtype = st.DataType
+	}
+	nBands := st.NBands
+	if po.bands != nil {
+		nBands = len(po.bands)
+	}
+	bands := make([]int, nBands)
+	for i := range bands {
+		if po.bands != nil {
+			bands[i] = po.bands[i] - 1
+		} else {
+			bands[i] = i
+		}
+	}
+	adviseOpts := []DatasetAdviseReadOption{Bands(bands...), ConfigOption(po.config...), ErrLogger(po.errorHandler)}
+	readOpts := []DatasetIOOption{Bands(bands...), ConfigOption(po.config...), ErrLogger(po.errorHandler)}
+
+	handles := []*Dataset{ds}
+	nWorkers := po.depth
+	if nWorkers > len(windows) {
+		nWorkers = len(windows)
+	}
+	if name := ds.Description(); name != "" {
+		for len(handles) < nWorkers {
+			h, err := Open(name, RasterOnly(), ConfigOption(po.config...))
+			if err != nil {
+				break
+			}
+			handles = append(handles, h)
+		}
+	}
+
+	read := func(h *Dataset, w Block) PrefetchedWindow {
+		res := PrefetchedWindow{Block: w}
+		if w.W <= 0 || w.H <= 0 {
+			res.Err = fmt.Errorf("invalid window size %dx%d", w.W, w.H)
+			return res
+		}
+		if res.Data, res.Err = newBuffer(po.dtype, w.W*w.H*nBands); res.Err != nil {
+			return res
+		}
+		if res.Err = h.AdviseRead(w.X0, w.Y0, w.W, w.H, adviseOpts...); res.Err != nil {
+			return res
+		}
+		res.Err = h.Read(w.X0, w.Y0, res.Data, w.W, w.H, readOpts...)
+		return res
+	}
+
+	results := make(chan PrefetchedWindow)
+	stopped := make(chan struct{})
+	var once sync.Once
+	stop := func() {
+		once.Do(func() { close(stopped) })
+	}
+	// slots[i] receives the i-th window once read. tokens limits the number of windows
+	// being read or waiting to be consumed to depth.
+	slots := make([]chan PrefetchedWindow, len(windows))
+	for i := range slots {
+		slots[i] = make(chan PrefetchedWindow, 1)
+	}
+	tokens := make(chan struct{}, po.depth)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for _, h := range handles {
+		wg.Add(1)
+		go func(h *Dataset) {
+			defer wg.Done()
+			if h != ds {
+				defer h.Close()
+			}
+			for i := range jobs {
+				slots[i] <- read(h, windows[i])
+			}
+		}(h)
+	}
+	go func() {
+		defer close(jobs)
+		for i := range windows {
+			select {
+			case tokens <- struct{}{}:
+			case <-stopped:
+				return
+			}
+			select {
+			case jobs <- i:
+			case <-stopped:
+				return
+			}
+		}
+	}()
+	go func() {
+		defer close(results)
+		defer wg.Wait()
+		for i := range windows {
+			var res PrefetchedWindow
+			select {
+			case res = <-slots[i]:
+			case <-stopped:
+				return
+			}
+			select {
+			case results <- res:
+				<-tokens
+			case <-stopped:
+				return
+			}
+		}
+	}()
+	return results, stop
+}
@@ -0,0 +1,64 @@
+// Copyright 2021 Airbus Defence and Space
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package godal
+
+import "sync"
+
+// ProgressFunc is a function that is periodically called by long running operations
+// with the completion ratio (in [0,1]) and an optional message. Returning false
+// interrupts the operation, which will then return an error.
+type ProgressFunc func(complete float64, message string) bool
+
+var progressMu sync.Mutex
+var progressIndex int
+var progressFuncs = make(map[int]ProgressFunc)
+
+func registerProgress(fn ProgressFunc) int {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	for progressIndex == 0 || progressFuncs[progressIndex] != nil {
+		progressIndex++
+	}
+	progressFuncs[progressIndex] = fn
+	return progressIndex
+}
+
+func getProgress(i int) ProgressFunc {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	return progressFuncs[i]
+}
+
+func unregisterProgress(i int) {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	delete(progressFuncs, i)
+}
+
+type progressOpt struct {
+	fn ProgressFunc
+}
+
+// Progress sets a function that will be called to report the progress of the operation.
+// See ProgressFunc.
+func Progress(fn ProgressFunc) interface {
+	CreateCopyOption
+} {
+	return progressOpt{fn}
+}
+
+func (po progressOpt) setCreateCopyOpt(o *createCopyOpts) {
+	o.progress = po.fn
+}
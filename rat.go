@@ -0,0 +1,239 @@
+// Copyright 2021 Airbus Defence and Space
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package godal
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// AttributeTable is a raster attribute table (RAT), associating a set of attributes
+// (e.g. a class name and color) to each pixel value or range of pixel values of a band.
+type AttributeTable struct {
+	TableType RATTableType
+	// LinearBinning is set when row i describes the pixel values in
+	// [Row0Min+i*BinSize, Row0Min+(i+1)*BinSize[, in which case the table does not
+	// need to contain RATMinMax, RATMin or RATMax columns.
+	LinearBinning    bool
+	Row0Min, BinSize float64
+	Columns          []RATColumn
+}
+
+// RATColumn is a column of an AttributeTable. Depending on Type, exactly one of Integers,
+// Reals or Strings contains the values of the column, one per table row.
+type RATColumn struct {
+	Name     string
+	Type     RATFieldType
+	Usage    RATFieldUsage
+	Integers []int
+	Reals    []float64
+	Strings  []string
+}
+
+// Len returns the number of rows of the column
+func (col RATColumn) Len() int {
+	switch col.Type {
+	case RATInteger:
+		return len(col.Integers)
+	case RATReal:
+		return len(col.Reals)
+	default:
+		return len(col.Strings)
+	}
+}
+
+// FloatValue returns the value of the given row converted to a float64
+func (col RATColumn) FloatValue(row int) float64 {
+	switch col.Type {
+	case RATInteger:
+		return float64(col.Integers[row])
+	case RATReal:
+		return col.Reals[row]
+	default:
+		v, err := strconv.ParseFloat(col.Strings[row], 64)
+		if err != nil {
+			return math.NaN()
+		}
+		return v
+	}
+}
+
+// StringValue returns the value of the given row converted to a string
+func (col RATColumn) StringValue(row int) string {
+	switch col.Type {
+	case RATInteger:
+		return strconv.Itoa(col.Integers[row])
+	case RATReal:
+		return strconv.FormatFloat(col.Reals[row], 'g', -1, 64)
+	default:
+		return col.Strings[row]
+	}
+}
+
+// RowCount returns the number of rows of the table
+func (at AttributeTable) RowCount() int {
+	if len(at.Columns) == 0 {
+		return 0
+	}
+	return at.Columns[0].Len()
+}
+
+// Column returns the index of the first column with the given usage, or -1 if the table
+// has no such column
+func (at AttributeTable) Column(usage RATFieldUsage) int {
+	for i, col := range at.Columns {
+		if col.Usage == usage {
+			return i
+		}
+	}
+	return -1
+}
+
+func (at AttributeTable) checkColumns() (int, error) {
+	n := at.RowCount()
+	for _, col := range at.Columns {
+		if col.Type != RATInteger && col.Type != RATReal && col.Type != RATString {
+			return 0, fmt.Errorf("column %s: invalid type %d", col.Name, col.Type)
+		}
+		if col.Len() != n {
+			return 0, fmt.Errorf("column %s has %d rows, expected %d", col.Name, col.Len(), n)
+		}
+	}
+	return n, nil
+}
+
+// rowRange returns the range of integer pixel values [min,max] described by the given row
+func (at AttributeTable) rowRange(row int) (int, int) {
+	if i := at.Column(RATMinMax); i >= 0 {
+		v := int(math.Round(at.Columns[i].FloatValue(row)))
+		return v, v
+	}
+	imin, imax := at.Column(RATMin), at.Column(RATMax)
+	if imin >= 0 && imax >= 0 {
+		return int(math.Ceil(at.Columns[imin].FloatValue(row))), int(math.Floor(at.Columns[imax].FloatValue(row)))
+	}
+	if at.LinearBinning {
+		start := at.Row0Min + float64(row)*at.BinSize
+		return int(math.Ceil(start)), int(math.Ceil(start+at.BinSize)) - 1
+	}
+	return row, row
+}
+
+// ColorTable builds an RGB ColorTable from the RATRed, RATGreen, RATBlue and optional
+// RATAlpha columns of the table. The pixel values described by each row are taken from
+// the RATMinMax column, or the RATMin and RATMax columns, or the linear binning, or the
+// row index, in this order of preference. Pixel values not described by the table are
+// assigned a fully transparent black color.
+func (at AttributeTable) ColorTable() (ColorTable, error) {
+	ir, ig, ib, ia := at.Column(RATRed), at.Column(RATGreen), at.Column(RATBlue), at.Column(RATAlpha)
+	if ir < 0 || ig < 0 || ib < 0 {
+		return ColorTable{}, fmt.Errorf("attribute table does not contain red, green and blue columns")
+	}
+	ct := ColorTable{PaletteInterp: RGBPalette}
+	for row := 0; row < at.RowCount(); row++ {
+		lo, hi := at.rowRange(row)
+		if lo < 0 || hi > 65535 {
+			return ColorTable{}, fmt.Errorf("row %d: pixel values [%d,%d] cannot be represented in a color table", row, lo, hi)
+		}
+		entry := [4]int16{
+			int16(at.Columns[ir].FloatValue(row)),
+			int16(at.Columns[ig].FloatValue(row)),
+			int16(at.Columns[ib].FloatValue(row)),
+			255,
+		}
+		if ia >= 0 {
+			entry[3] = int16(at.Columns[ia].FloatValue(row))
+		}
+		for len(ct.Entries) <= hi {
+			ct.Entries = append(ct.Entries, [4]int16{})
+		}
+		for v := lo; v <= hi; v++ {
+			ct.Entries[v] = entry
+		}
+	}
+	return ct, nil
+}
+
+// CategoryNames returns the class names contained in the RATName column of the table,
+// indexed by pixel value. The pixel values described by each row are resolved as in
+// ColorTable(). Pixel values not described by the table are assigned an empty name.
+func (at AttributeTable) CategoryNames() ([]string, error) {
+	in := at.Column(RATName)
+	if in < 0 {
+		return nil, fmt.Errorf("attribute table does not contain a name column")
+	}
+	names := []string{}
+	for row := 0; row < at.RowCount(); row++ {
+		lo, hi := at.rowRange(row)
+		if lo < 0 || hi > 65535 {
+			return nil, fmt.Errorf("row %d: pixel values [%d,%d] cannot be represented as categories", row, lo, hi)
+		}
+		for len(names) <= hi {
+			names = append(names, "")
+		}
+		for v := lo; v <= hi; v++ {
+			names[v] = at.Columns[in].StringValue(row)
+		}
+	}
+	return names, nil
+}
+
+// AttributeTableFromColorTable creates a thematic AttributeTable with a "Value" RATMinMax
+// column and "Red", "Green", "Blue" and "Alpha" columns, containing one row per entry of
+// the RGB color table ct.
+func AttributeTableFromColorTable(ct ColorTable) (AttributeTable, error) {
+	if ct.PaletteInterp != RGBPalette {
+		return AttributeTable{}, fmt.Errorf("only RGB color tables can be converted")
+	}
+	at := AttributeTable{
+		TableType: ThematicTable,
+		Columns: []RATColumn{
+			{Name: "Value", Type: RATInteger, Usage: RATMinMax},
+			{Name: "Red", Type: RATInteger, Usage: RATRed},
+			{Name: "Green", Type: RATInteger, Usage: RATGreen},
+			{Name: "Blue", Type: RATInteger, Usage: RATBlue},
+			{Name: "Alpha", Type: RATInteger, Usage: RATAlpha},
+		},
+	}
+	for i, e := range ct.Entries {
+		at.Columns[0].Integers = append(at.Columns[0].Integers, i)
+		for c := 0; c < 4; c++ {
+			at.Columns[c+1].Integers = append(at.Columns[c+1].Integers, int(e[c]))
+		}
+	}
+	return at, nil
+}
+
+// AttributeTableFromCategoryNames creates a thematic AttributeTable with a "Value" RATMinMax
+// column and a "Name" column, containing one row per non-empty name (the pixel value being
+// the index of the name in names).
+func AttributeTableFromCategoryNames(names []string) AttributeTable {
+	at := AttributeTable{
+		TableType: ThematicTable,
+		Columns: []RATColumn{
+			{Name: "Value", Type: RATInteger, Usage: RATMinMax, Integers: []int{}},
+			{Name: "Name", Type: RATString, Usage: RATName, Strings: []string{}},
+		},
+	}
+	for i, name := range names {
+		if name == "" {
+			continue
+		}
+		at.Columns[0].Integers = append(at.Columns[0].Integers, i)
+		at.Columns[1].Strings = append(at.Columns[1].Strings, name)
+	}
+	return at
+}
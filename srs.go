@@ -14,51 +14,298 @@
 
 package godal
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"strings"
+)
 
 type srWKTOpts struct {
+	format       WKTVersion
+	multiline    bool
 	errorHandler ErrorHandler
 }
 
 //WKTExportOption is an option that can be passed to SpatialRef.WKT()
 //
 // Available WKTExportOptions are:
+//  - WKTFormat
+//  - Multiline
 //  - ErrLogger
 type WKTExportOption interface {
 	setWKTExportOpt(sro *srWKTOpts)
 }
 
+// WKTVersion is a flavour of the WKT representation of a SpatialRef
+type WKTVersion string
+
+const (
+	// WKT1 is the WKT1 representation, as extended by GDAL
+	WKT1 WKTVersion = "WKT1"
+	// WKT1Simple is a simplified WKT1 representation, without AXIS, TOWGS84, AUTHORITY or EXTENSION nodes
+	WKT1Simple WKTVersion = "WKT1_SIMPLE"
+	// WKT1ESRI is the WKT1 representation used by ESRI (e.g. in .prj files)
+	WKT1ESRI WKTVersion = "WKT1_ESRI"
+	// WKT2_2015 is the ISO 19162:2015 WKT2 representation
+	WKT2_2015 WKTVersion = "WKT2_2015"
+	// WKT2_2019 is the ISO 19162:2019 WKT2 representation (named WKT2_2018 by gdal < 3.1)
+	WKT2_2019 WKTVersion = "WKT2_2019"
+	// WKT2 is the most recent WKT2 representation supported by GDAL
+	WKT2 WKTVersion = "WKT2"
+)
+
+type wktFormatOpt struct {
+	format WKTVersion
+}
+
+// WKTFormat sets the WKT flavour used by SpatialRef.WKT(). If not set, WKT1 is used
+// unless the SpatialRef cannot be represented in WKT1, in which case WKT2 is used.
+func WKTFormat(format WKTVersion) interface {
+	WKTExportOption
+} {
+	return wktFormatOpt{format}
+}
+
+func (wo wktFormatOpt) setWKTExportOpt(o *srWKTOpts) {
+	o.format = wo.format
+}
+
+type multilineOpt struct{}
+
+// Multiline pretty-prints the exported WKT or PROJJSON on multiple indented lines
+func Multiline() interface {
+	WKTExportOption
+	PROJJSONExportOption
+} {
+	return multilineOpt{}
+}
+
+func (mo multilineOpt) setWKTExportOpt(o *srWKTOpts) {
+	o.multiline = true
+}
+func (mo multilineOpt) setPROJJSONExportOpt(o *srPROJJSONOpts) {
+	o.multiline = true
+}
+
+type srPROJJSONOpts struct {
+	multiline    bool
+	errorHandler ErrorHandler
+}
+
+// PROJJSONExportOption is an option that can be passed to SpatialRef.PROJJSON()
+//
+// Available PROJJSONExportOptions are:
+//  - Multiline
+//  - ErrLogger
+type PROJJSONExportOption interface {
+	setPROJJSONExportOpt(o *srPROJJSONOpts)
+}
+
+type srProj4Opts struct {
+	errorHandler ErrorHandler
+}
+
+// Proj4ExportOption is an option that can be passed to SpatialRef.Proj4()
+//
+// Available Proj4ExportOptions are:
+//  - ErrLogger
+type Proj4ExportOption interface {
+	setProj4ExportOpt(o *srProj4Opts)
+}
+
+// AreaOfUse is the geographic area, in WGS84 degrees, in which a SpatialRef is valid.
+// West may be greater than East for areas crossing the antimeridian.
+type AreaOfUse struct {
+	Name                     string
+	West, South, East, North float64
+}
+
 type trnOpts struct {
+	aoi          *[4]float64
+	accuracy     float64
+	ballpark     *bool
+	pipeline     string
+	reverse      bool
 	errorHandler ErrorHandler
 }
 
 // TransformOption is an option that can be passed to NewTransform
 //
 // Available TransformOptions are:
+//  - AreaOfInterest
+//  - DesiredAccuracy
+//  - AllowBallpark
+//  - ProjPipeline
 //  - ErrLogger
 type TransformOption interface {
 	setTransformOpt(o *trnOpts)
 }
 
+// CoordinateOperationsOption is an option that can be passed to CoordinateOperations
+//
+// Available CoordinateOperationsOptions are:
+//  - AreaOfInterest
+//  - DesiredAccuracy
+//  - AllowBallpark
+//  - ErrLogger
+type CoordinateOperationsOption interface {
+	setCoordinateOperationsOpt(o *trnOpts)
+}
+
+type aoiOpt struct {
+	aoi [4]float64
+}
+
+// AreaOfInterest restricts the candidate coordinate operations, or the CRSs returned by
+// ListCRS, to the ones whose area of use intersects the given area, expressed in WGS84
+// degrees. west may be greater than east for an area crossing the antimeridian.
+func AreaOfInterest(west, south, east, north float64) interface {
+	TransformOption
+	CoordinateOperationsOption
+	ListCRSOption
+} {
+	return aoiOpt{[4]float64{west, south, east, north}}
+}
+
+func (ao aoiOpt) setTransformOpt(o *trnOpts) {
+	o.aoi = &ao.aoi
+}
+func (ao aoiOpt) setCoordinateOperationsOpt(o *trnOpts) {
+	o.aoi = &ao.aoi
+}
+func (ao aoiOpt) setListCRSOpt(o *listCRSOpts) {
+	o.aoi = &ao.aoi
+}
+
+type accuracyOpt struct {
+	meters float64
+}
+
+// DesiredAccuracy restricts the candidate coordinate operations to the ones whose accuracy
+// is known and better than the given value, in meters. Requires GDAL >= 3.3.
+func DesiredAccuracy(meters float64) interface {
+	TransformOption
+	CoordinateOperationsOption
+} {
+	return accuracyOpt{meters}
+}
+
+func (ao accuracyOpt) setTransformOpt(o *trnOpts) {
+	o.accuracy = ao.meters
+}
+func (ao accuracyOpt) setCoordinateOperationsOpt(o *trnOpts) {
+	o.accuracy = ao.meters
+}
+
+type ballparkOpt struct {
+	allow bool
+}
+
+// AllowBallpark sets whether ballpark transformations, i.e. approximate transformations
+// ignoring datum shifts, may be used when no better coordinate operation is available.
+// They are allowed by default. Requires GDAL >= 3.3 (and PROJ >= 8.1 for CoordinateOperations).
+func AllowBallpark(allow bool) interface {
+	TransformOption
+	CoordinateOperationsOption
+} {
+	return ballparkOpt{allow}
+}
+
+func (bo ballparkOpt) setTransformOpt(o *trnOpts) {
+	o.ballpark = &bo.allow
+}
+func (bo ballparkOpt) setCoordinateOperationsOpt(o *trnOpts) {
+	o.ballpark = &bo.allow
+}
+
+type pipelineOpt struct {
+	pipeline string
+	reverse  bool
+}
+
+// ProjPipeline forces the coordinate operation used by the Transform, as a PROJ string
+// (e.g. "+proj=pipeline +step ..."), WKT2 or PROJJSON coordinate operation, bypassing
+// the automatic operation selection. If reverse is set, the operation is applied in
+// the reverse direction.
+func ProjPipeline(pipeline string, reverse bool) interface {
+	TransformOption
+} {
+	return pipelineOpt{pipeline, reverse}
+}
+
+func (po pipelineOpt) setTransformOpt(o *trnOpts) {
+	o.pipeline = po.pipeline
+	o.reverse = po.reverse
+}
+
+// CoordinateOperation describes a coordinate operation, i.e. a conversion or
+// transformation pipeline between two SpatialRefs
+type CoordinateOperation struct {
+	Name string
+	// Proj is the PROJ pipeline of the operation, or an empty string if it cannot be
+	// represented as such
+	Proj string
+	// Accuracy is the accuracy of the operation in meters, or -1 if unknown
+	Accuracy float64
+	// Instantiable is false when the operation requires grids that are not available
+	Instantiable bool
+	// AreaOfUse is the area in which the operation is valid, or nil if unknown
+	AreaOfUse *AreaOfUse
+}
+
 func (sr *SpatialRef) setBoundsOpt(o *boundsOpts) {
 	o.sr = sr
 }
 
+// defaultDensifyPts is the default number of points added along each edge of a bounding
+// box when reprojecting it
+const defaultDensifyPts = 21
+
 type boundsOpts struct {
 	sr           *SpatialRef
+	densifyPts   int
 	errorHandler ErrorHandler
 }
 
-// BoundsOption is an option that can be passed to Dataset.Bounds or Geometry.Bounds
+// BoundsOption is an option that can be passed to Dataset.Bounds, Dataset.WindowBounds,
+// Layer.Bounds or Geometry.Bounds
 //
 // Available options are:
 //  - *SpatialRef
+//  - DensifyPoints
 //  - ErrLogger
+//
+// When reprojected to a geographic SpatialRef, the returned longitude bounds may have min > max,
+// which denotes a bounding box crossing the antimeridian.
 type BoundsOption interface {
 	setBoundsOpt(o *boundsOpts)
 }
 
+type densifyPtsOpt struct {
+	n int
+}
+
+// DensifyPoints sets the number of points added along each edge of a bounding box
+// when it is reprojected to another SpatialRef, in order to account for the curvature
+// of the edges once reprojected. 0 means that only the corners are reprojected.
+//
+// Defaults to 21
+func DensifyPoints(n int) interface {
+	BoundsOption
+	WindowFromBoundsOption
+} {
+	return densifyPtsOpt{n}
+}
+
+func (do densifyPtsOpt) setBoundsOpt(o *boundsOpts) {
+	o.densifyPts = do.n
+}
+func (do densifyPtsOpt) setWindowFromBoundsOpt(o *windowFromBoundsOpts) {
+	o.densifyPts = do.n
+}
+
 type createSpatialRefOpts struct {
+	axisStrategy AxisMappingStrategy
 	errorHandler ErrorHandler
 }
 
@@ -66,41 +313,379 @@ type createSpatialRefOpts struct {
 // reference object
 //
 // Available options are:
+//  - AxisMapping
 //  - ErrLogger
 type CreateSpatialRefOption interface {
 	setCreateSpatialRefOpt(so *createSpatialRefOpts)
 }
 
-func reprojectBounds(bnds [4]float64, src, dst *SpatialRef) ([4]float64, error) {
-	var ret [4]float64
-	trn, err := NewTransform(src, dst)
+type axisMappingOpt struct {
+	strategy AxisMappingStrategy
+}
+
+// AxisMapping sets the AxisMappingStrategy of the created SpatialRef, overriding the
+// package default set with SetDefaultAxisMappingStrategy
+func AxisMapping(strategy AxisMappingStrategy) interface {
+	CreateSpatialRefOption
+} {
+	return axisMappingOpt{strategy}
+}
+
+func (ao axisMappingOpt) setCreateSpatialRefOpt(o *createSpatialRefOpts) {
+	o.axisStrategy = ao.strategy
+}
+
+// reprojectBounds reprojects bnds from src to dst, sampling densifyPts additional points
+// along each edge. If dst is geographic, the returned bounds have minx > maxx when they
+// cross the antimeridian. eh, if not nil, receives the errors emitted by gdal.
+func reprojectBounds(bnds [4]float64, src, dst *SpatialRef, densifyPts int, eh ErrorHandler) ([4]float64, error) {
+	if densifyPts < 0 {
+		return bnds, fmt.Errorf("invalid number of densification points %d", densifyPts)
+	}
+	topts := []TransformOption{}
+	if eh != nil {
+		topts = append(topts, errorCallback{eh})
+	}
+	trn, err := NewTransform(src, dst, topts...)
 	if err != nil {
-		return ret, fmt.Errorf("create coordinate transform: %w", err)
+		return bnds, fmt.Errorf("create coordinate transform: %w", err)
 	}
 	defer trn.Close()
-	x := []float64{bnds[0], bnds[0], bnds[2], bnds[2]}
-	y := []float64{bnds[1], bnds[3], bnds[3], bnds[1]}
-	err = trn.TransformEx(x, y, nil, nil)
+	ret, ok, err := trn.transformBounds(bnds, densifyPts)
 	if err != nil {
-		return ret, fmt.Errorf("reproject bounds: %w", err)
-	}
-	ret[0] = x[0]
-	ret[1] = y[0]
-	ret[2] = x[0]
-	ret[3] = y[0]
-	for i := 1; i < 4; i++ {
-		if x[i] < ret[0] {
-			ret[0] = x[i]
+		return bnds, fmt.Errorf("reproject bounds: %w", err)
+	}
+	if ok {
+		return ret, nil
+	}
+	return densifiedBounds(trn, bnds, src, dst, densifyPts)
+}
+
+// densifiedBounds is the fallback of reprojectBounds for GDAL versions not providing
+// OCTTransformBounds
+func densifiedBounds(trn *Transform, bnds [4]float64, src, dst *SpatialRef, densifyPts int) ([4]float64, error) {
+	minx, miny, maxx, maxy := bnds[0], bnds[1], bnds[2], bnds[3]
+	if src.Geographic() {
+		if src.longitudeFirst() && minx > maxx {
+			maxx += 360
+		} else if !src.longitudeFirst() && miny > maxy {
+			maxy += 360
+		}
+	}
+	// sample the edges of the box as a ring, in order
+	n := densifyPts + 1
+	x := make([]float64, 0, 4*n)
+	y := make([]float64, 0, 4*n)
+	for i := 0; i < n; i++ {
+		f := float64(i) / float64(n)
+		x = append(x, minx+f*(maxx-minx))
+		y = append(y, miny)
+	}
+	for i := 0; i < n; i++ {
+		f := float64(i) / float64(n)
+		x = append(x, maxx)
+		y = append(y, miny+f*(maxy-miny))
+	}
+	for i := 0; i < n; i++ {
+		f := float64(i) / float64(n)
+		x = append(x, maxx-f*(maxx-minx))
+		y = append(y, maxy)
+	}
+	for i := 0; i < n; i++ {
+		f := float64(i) / float64(n)
+		x = append(x, minx)
+		y = append(y, maxy-f*(maxy-miny))
+	}
+	if err := trn.TransformEx(x, y, nil, nil); err != nil {
+		return bnds, fmt.Errorf("reproject bounds: %w", err)
+	}
+	ret := envelope(x, y)
+	if !dst.Geographic() {
+		return ret, nil
+	}
+	// indexes of the longitude and latitude in the data axis order of dst
+	ilon, ilat := 0, 1
+	lons := x
+	if !dst.longitudeFirst() {
+		ilon, ilat = 1, 0
+		lons = y
+	}
+	// a jump of more than 180° between consecutive samples means the ring crosses the antimeridian
+	crosses := false
+	for i := range lons {
+		if math.Abs(lons[(i+1)%len(lons)]-lons[i]) > 180 {
+			crosses = true
+			break
 		}
-		if x[i] > ret[2] {
-			ret[2] = x[i]
+	}
+	if crosses {
+		ret[ilon], ret[ilon+2] = math.Inf(1), math.Inf(-1)
+		for _, v := range lons {
+			if v < 0 {
+				v += 360
+			}
+			ret[ilon] = math.Min(ret[ilon], v)
+			ret[ilon+2] = math.Max(ret[ilon+2], v)
 		}
-		if y[i] < ret[1] {
-			ret[1] = y[i]
+		if ret[ilon+2] > 180 {
+			ret[ilon+2] -= 360
 		}
-		if y[i] > ret[3] {
-			ret[3] = y[i]
+	}
+	// a box containing a pole spans all longitudes
+	inv, err := NewTransform(dst, src)
+	if err != nil {
+		return ret, nil
+	}
+	defer inv.Close()
+	px, py := []float64{0, 0}, []float64{90, -90}
+	if ilon == 1 {
+		px, py = py, px
+	}
+	pok := []bool{false, false}
+	_ = inv.TransformEx(px, py, nil, pok)
+	for i, lat := range []float64{90, -90} {
+		if pok[i] && px[i] >= minx && px[i] <= maxx && py[i] >= miny && py[i] <= maxy {
+			ret[ilon], ret[ilon+2] = -180, 180
+			if lat > 0 {
+				ret[ilat+2] = 90
+			} else {
+				ret[ilat] = -90
+			}
 		}
 	}
 	return ret, nil
 }
+
+// CRSInfo describes a CRS listed in the PROJ database
+type CRSInfo struct {
+	Authority  string
+	Code       string
+	Name       string
+	Type       CRSType
+	Deprecated bool
+	// AreaOfUse is nil if the database does not define an area of use for the CRS
+	AreaOfUse *AreaOfUse
+	// ProjectionMethod is the name of the projection method of projected CRSs, empty otherwise
+	ProjectionMethod string
+}
+
+type listCRSOpts struct {
+	authority    string
+	name         string
+	types        []CRSType
+	aoi          *[4]float64
+	errorHandler ErrorHandler
+}
+
+// ListCRSOption is an option that can be passed to ListCRS
+//
+// Available ListCRSOptions are:
+//  - Authority
+//  - NameContains
+//  - CRSTypes
+//  - AreaOfInterest
+//  - ErrLogger
+type ListCRSOption interface {
+	setListCRSOpt(o *listCRSOpts)
+}
+
+// SpatialRefMatch is a candidate CRS of the PROJ database matching a SpatialRef, as returned
+// by SpatialRef.FindMatches
+type SpatialRefMatch struct {
+	Authority string
+	Code      string
+	Name      string
+	// Confidence is the confidence of the match, from 0 to 100
+	Confidence int
+}
+
+type findMatchesOpts struct {
+	authority    string
+	errorHandler ErrorHandler
+}
+
+// FindMatchesOption is an option that can be passed to SpatialRef.FindMatches
+//
+// Available FindMatchesOptions are:
+//  - Authority
+//  - ErrLogger
+type FindMatchesOption interface {
+	setFindMatchesOpt(o *findMatchesOpts)
+}
+
+type authorityOpt struct {
+	name string
+}
+
+// Authority restricts the returned CRSs to the ones defined by the given authority, e.g.
+// "EPSG" or "ESRI".
+func Authority(name string) interface {
+	ListCRSOption
+	FindMatchesOption
+} {
+	return authorityOpt{name}
+}
+
+func (ao authorityOpt) setListCRSOpt(o *listCRSOpts) {
+	o.authority = ao.name
+}
+func (ao authorityOpt) setFindMatchesOpt(o *findMatchesOpts) {
+	o.authority = ao.name
+}
+
+type nameContainsOpt struct {
+	name string
+}
+
+// NameContains restricts the returned CRSs to the ones whose name contains the given
+// string, ignoring case.
+func NameContains(name string) ListCRSOption {
+	return nameContainsOpt{name}
+}
+
+func (no nameContainsOpt) setListCRSOpt(o *listCRSOpts) {
+	o.name = no.name
+}
+
+type crsTypesOpt struct {
+	types []CRSType
+}
+
+// CRSTypes restricts the returned CRSs to the ones of the given types.
+func CRSTypes(types ...CRSType) ListCRSOption {
+	return crsTypesOpt{types}
+}
+
+func (to crsTypesOpt) setListCRSOpt(o *listCRSOpts) {
+	o.types = to.types
+}
+
+// lonLatIntersects returns whether two west,south,east,north areas in degrees intersect,
+// either of them possibly crossing the antimeridian (i.e. west > east)
+func lonLatIntersects(a, b [4]float64) bool {
+	if a[1] > b[3] || b[1] > a[3] {
+		return false
+	}
+	lonRanges := func(w, e float64) [][2]float64 {
+		if w > e {
+			return [][2]float64{{w, 180}, {-180, e}}
+		}
+		return [][2]float64{{w, e}}
+	}
+	for _, ra := range lonRanges(a[0], a[2]) {
+		for _, rb := range lonRanges(b[0], b[2]) {
+			if ra[0] <= rb[1] && rb[0] <= ra[1] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (lo *listCRSOpts) match(info *CRSInfo) bool {
+	if lo.name != "" && !strings.Contains(strings.ToLower(info.Name), strings.ToLower(lo.name)) {
+		return false
+	}
+	if len(lo.types) > 0 {
+		found := false
+		for _, t := range lo.types {
+			if t == info.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if lo.aoi != nil {
+		if info.AreaOfUse == nil {
+			return false
+		}
+		aou := [4]float64{info.AreaOfUse.West, info.AreaOfUse.South, info.AreaOfUse.East, info.AreaOfUse.North}
+		if !lonLatIntersects(*lo.aoi, aou) {
+			return false
+		}
+	}
+	return true
+}
+
+// SameCriterion is the criterion used by SpatialRef.IsSame to compare two SpatialRefs
+type SameCriterion string
+
+const (
+	// SameStrict requires both SpatialRefs to be strictly identical, including their names
+	SameStrict SameCriterion = "STRICT"
+	// SameEquivalent requires both SpatialRefs to be equivalent, i.e. to produce the same
+	// coordinates, regardless of e.g. their names
+	SameEquivalent SameCriterion = "EQUIVALENT"
+	// SameEquivalentExceptAxisOrder is like SameEquivalent, but also ignores the axis order
+	// of geographic SpatialRefs. It is the default criterion.
+	SameEquivalentExceptAxisOrder SameCriterion = "EQUIVALENT_EXCEPT_AXIS_ORDER_GEOGCRS"
+)
+
+type isSameOpts struct {
+	criterion         SameCriterion
+	ignoreAxisMapping bool
+	ignoreEpoch       bool
+}
+
+// IsSameOption is an option that can be passed to SpatialRef.IsSame
+//
+// Available IsSameOptions are:
+//  - Criterion
+//  - IgnoreAxisMapping
+//  - IgnoreCoordinateEpoch
+type IsSameOption interface {
+	setIsSameOpt(o *isSameOpts)
+}
+
+func (io isSameOpts) options() []string {
+	opts := []string{"CRITERION=" + string(io.criterion)}
+	if io.ignoreAxisMapping {
+		opts = append(opts, "IGNORE_DATA_AXIS_TO_SRS_AXIS_MAPPING=YES")
+	}
+	if io.ignoreEpoch {
+		opts = append(opts, "IGNORE_COORDINATE_EPOCH=YES")
+	}
+	return opts
+}
+
+type criterionOpt struct {
+	criterion SameCriterion
+}
+
+// Criterion sets the criterion used to compare SpatialRefs. Defaults to
+// SameEquivalentExceptAxisOrder.
+func Criterion(criterion SameCriterion) IsSameOption {
+	return criterionOpt{criterion}
+}
+
+func (co criterionOpt) setIsSameOpt(o *isSameOpts) {
+	o.criterion = co.criterion
+}
+
+type ignoreAxisMappingOpt struct{}
+
+// IgnoreAxisMapping makes IsSame ignore the AxisMappingStrategy of the SpatialRefs, i.e.
+// a SpatialRef with the TraditionalGISOrder strategy and one with the AuthorityCompliant
+// strategy are reported as identical if they describe the same CRS.
+func IgnoreAxisMapping() IsSameOption {
+	return ignoreAxisMappingOpt{}
+}
+
+func (ignoreAxisMappingOpt) setIsSameOpt(o *isSameOpts) {
+	o.ignoreAxisMapping = true
+}
+
+type ignoreCoordinateEpochOpt struct{}
+
+// IgnoreCoordinateEpoch makes IsSame ignore the coordinate epochs of the SpatialRefs.
+// Requires GDAL >= 3.4, coordinate epochs being always ignored with older versions.
+func IgnoreCoordinateEpoch() IsSameOption {
+	return ignoreCoordinateEpochOpt{}
+}
+
+func (ignoreCoordinateEpochOpt) setIsSameOpt(o *isSameOpts) {
+	o.ignoreEpoch = true
+}
@@ -38,6 +38,24 @@ func (aoo approximateOkOption) setStatisticsOpt(so *statisticsOpts) {
 	so.approx = 1
 }
 
+type computeMinMaxOpts struct {
+	approx       int
+	errorHandler ErrorHandler
+}
+
+// ComputeMinMaxOption is an option that can be passed to Band.ComputeMinMax()
+//
+// Available ComputeMinMaxOptions are:
+//   - Approximate
+//   - ErrLogger
+type ComputeMinMaxOption interface {
+	setComputeMinMaxOpt(cmo *computeMinMaxOpts)
+}
+
+func (aoo approximateOkOption) setComputeMinMaxOpt(cmo *computeMinMaxOpts) {
+	cmo.approx = 1
+}
+
 //SetStatistics is an option that can passed to Band.SetStatistics()
 //Available options are:
 //  -ErrLogger